@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"lnb/pkg/lnb"
+)
+
+// takeFlag removes "--name value" or "--name=value" from args and returns the
+// value. Arguments after a "--" separator are never treated as flags.
+func takeFlag(args []string, name string) (string, []string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, append(append([]string{}, args[:i]...), args[i+1:]...), true
+		}
+		if arg == name && i+1 < len(args) {
+			return args[i+1], append(append([]string{}, args[:i]...), args[i+2:]...), true
+		}
+	}
+	return "", args, false
+}
+
+// takeRepeatedFlag removes every "--name value" or "--name=value" from args
+// and returns the values in order
+func takeRepeatedFlag(args []string, name string) ([]string, []string) {
+	var values []string
+	for {
+		value, rest, ok := takeFlag(args, name)
+		if !ok {
+			return values, args
+		}
+		values = append(values, value)
+		args = rest
+	}
+}
+
+// takeBoolFlag removes "--name" from args and reports whether it was present
+func takeBoolFlag(args []string, name string) (bool, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == name {
+			return true, append(append([]string{}, args[:i]...), args[i+1:]...)
+		}
+	}
+	return false, args
+}
+
+// dropSeparator removes the first "--" separator once all flags have been taken
+func dropSeparator(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return append(append([]string{}, args[:i]...), args[i+1:]...)
+		}
+	}
+	return args
+}
+
+// takeOptions removes the flags that tune how an entry is created and returns
+// them as client options, exiting on an invalid value
+func takeOptions(args []string) (lnb.Options, []string) {
+	var opts lnb.Options
+	shim, args, _ := takeFlag(args, "--shim")
+	style, err := lnb.ParseShimStyle(shim)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if shim != "" {
+		opts.Shim = style
+	}
+	opts.Posix, args = takeBoolFlag(args, "--sh")
+	opts.Desktop, args = takeBoolFlag(args, "--desktop")
+	opts.Literal, args = takeBoolFlag(args, "--literal")
+	opts.Description, args, _ = takeFlag(args, "--desc")
+	opts.Tags, args = takeRepeatedFlag(args, "--tag")
+	return opts, args
+}
+
+// takeVariants removes "--linux", "--darwin" and "--windows <command>" from
+// args and returns them as per-OS command variants for an alias
+func takeVariants(args []string, literal bool) (map[string]*lnb.Command, []string) {
+	parse := lnb.ParseCommand
+	if literal {
+		parse = lnb.LiteralCommand
+	}
+
+	var variants map[string]*lnb.Command
+	for _, goos := range lnb.VariantOSes {
+		line, rest, ok := takeFlag(args, "--"+goos)
+		args = rest
+		if !ok {
+			continue
+		}
+		if variants == nil {
+			variants = make(map[string]*lnb.Command)
+		}
+		variants[goos] = parse(line)
+	}
+	return variants, args
+}
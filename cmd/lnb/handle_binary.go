@@ -1,11 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 
-	"lnb/internal/oshandler"
+	"lnb/pkg/lnb"
 )
 
 // getBinaryPath prompts for or gets the binary path from arguments
@@ -31,68 +31,99 @@ func getBinaryPath(command string, args []string) string {
 	return args[0]
 }
 
-// validateBinaryExists checks if the binary file exists
-func validateBinaryExists(filename string) {
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		fmt.Printf("Error: File '%s' does not exist.\n", filename)
+// getClient creates the lnb client for the current OS
+func getClient() *lnb.Client {
+	client, err := lnb.New()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+	return client
 }
 
-// getAbsolutePath converts relative path to absolute path
-func getAbsolutePath(filename string) string {
-	absPath, err := filepath.Abs(filename)
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+// exitWithError prints an error from the client, with a hint where one helps, and exits
+func exitWithError(err error) {
+	fmt.Printf("Error: %v\n", err)
+	if errors.Is(err, lnb.ErrShadowed) {
+		fmt.Println("Use --allow-shadow to create it anyway, or 'lnb which <name>' to see the full lookup chain.")
 	}
-	return absPath
+	os.Exit(1)
 }
 
-// getOSHandler creates and returns the appropriate OS handler
-func getOSHandler() oshandler.Handler {
-	handler := oshandler.New()
-	if handler == nil {
-		fmt.Println("Error: Unsupported operating system")
-		os.Exit(1)
+// printResult prints the warnings and notes collected by an operation
+func printResult(result *lnb.Result) {
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	for _, note := range result.Notes {
+		fmt.Printf("🔧 %s\n", note)
 	}
-	return handler
 }
 
 // handleInstallBinary handles the installation of a binary
-func handleInstallBinary(filename string) {
-	validateBinaryExists(filename)
-	absPath := getAbsolutePath(filename)
-	handler := getOSHandler()
+func handleInstallBinary(filename, name string, allowShadow bool, opts lnb.Options) {
+	client := getClient()
+	client.AllowShadow = allowShadow
+	client.Options = opts
 
-	if err := handler.Handle(absPath, "install"); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+	result, err := client.InstallAs(filename, name)
+	if err != nil {
+		exitWithError(err)
 	}
 
-	fmt.Printf("✅ Successfully installed '%s'\n", filepath.Base(filename))
+	printResult(result)
+	fmt.Printf("Installed: %s -> %s\n", result.TargetPath, result.SourcePath)
+	fmt.Printf("✅ Successfully installed '%s'\n", result.Name)
+	offerShellPath()
 }
 
-// handleRemoveBinary handles the removal of a binary
+// handleRemoveBinary handles the removal of a binary or alias
 func handleRemoveBinary(filename string) {
-	absPath := getAbsolutePath(filename)
-	handler := getOSHandler()
-
-	if err := handler.Handle(absPath, "remove"); err != nil {
+	result, err := getClient().Remove(filename)
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Successfully removed '%s'\n", filepath.Base(filename))
+	printResult(result)
+	fmt.Printf("Removed: %s\n", result.TargetPath)
+	fmt.Printf("✅ Successfully removed '%s'\n", result.Name)
+}
+
+// handleRemoveTagged removes every entry with a tag
+func handleRemoveTagged(tag string, args []string) {
+	if len(dropSeparator(args)) > 0 {
+		fmt.Println("Error: remove --tag does not take a name as well.")
+		fmt.Println("Usage: lnb remove --tag <tag>")
+		os.Exit(1)
+	}
+
+	results, err := getClient().RemoveTagged(tag)
+	for _, result := range results {
+		printResult(result)
+		fmt.Printf("Removed: %s\n", result.TargetPath)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("✅ Successfully removed %d entries tagged '%s'\n", len(results), tag)
 }
 
 // handleBinaryCommand handles install and remove commands for binaries
 func handleBinaryCommand(command string, args []string) {
-	filename := getBinaryPath(command, args)
+	if tag, rest, ok := takeFlag(args, "--tag"); ok && command == "remove" {
+		handleRemoveTagged(tag, rest)
+		return
+	}
+
+	name, args, _ := takeFlag(args, "--as")
+	allowShadow, args := takeBoolFlag(args, "--allow-shadow")
+	opts, args := takeOptions(args)
+	filename := getBinaryPath(command, dropSeparator(args))
 
 	switch command {
 	case "install":
-		handleInstallBinary(filename)
+		handleInstallBinary(filename, name, allowShadow, opts)
 	case "remove":
 		handleRemoveBinary(filename)
 	default:
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"lnb/pkg/lnb"
+)
+
+// handleContainerAliasCommand handles container-alias <name> <image> [-- <cmd>...]
+func handleContainerAliasCommand(args []string) {
+	runtime, args, _ := takeFlag(args, "--runtime")
+	envFlags, args := takeRepeatedFlag(args, "--env")
+	workdir, args, _ := takeFlag(args, "--workdir")
+	runArgs, args := takeRepeatedFlag(args, "--run-arg")
+	allowShadow, args := takeBoolFlag(args, "--allow-shadow")
+	opts, args := takeOptions(args)
+
+	positional, cmd := splitAtSeparator(args)
+	if len(positional) != 2 {
+		fmt.Println("Error: container-alias requires a name and an image.")
+		fmt.Println("Usage: lnb container-alias <name> <image> [--runtime docker|podman] [--env NAME] [-- <cmd>...]")
+		os.Exit(1)
+	}
+
+	var env []string
+	for _, flag := range envFlags {
+		for _, name := range strings.Split(flag, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				env = append(env, name)
+			}
+		}
+	}
+
+	spec := &lnb.Container{
+		Image:   positional[1],
+		Runtime: runtime,
+		Env:     env,
+		Workdir: workdir,
+		RunArgs: runArgs,
+		Cmd:     cmd,
+	}
+
+	client := getClient()
+	client.AllowShadow = allowShadow
+	client.Options = opts
+
+	result, err := client.ContainerAlias(positional[0], spec)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	printResult(result)
+	fmt.Printf("Created container alias: %s -> %s\n", result.Name, result.Command)
+	fmt.Printf("✅ Successfully created container alias '%s' for image '%s'\n", result.Name, spec.Image)
+	offerShellPath()
+}
+
+// splitAtSeparator splits args at the first "--" into what comes before and after it
+func splitAtSeparator(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
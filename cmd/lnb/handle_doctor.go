@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// handleDoctorCommand checks the bin directory and every entry for problems
+func handleDoctorCommand() {
+	report, err := getClient().Doctor()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Bin directory: %s\n", report.BinDir)
+	fmt.Printf("Checked %d entries\n\n", report.Checked)
+
+	if report.OK() {
+		fmt.Println("✅ No problems found")
+		return
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Name == "" {
+			fmt.Printf("  ⚠️  %s\n", issue.Problem)
+		} else {
+			fmt.Printf("  ⚠️  %s: %s\n", issue.Name, issue.Problem)
+		}
+	}
+	fmt.Printf("\n%d problem(s) found\n", len(report.Issues))
+	os.Exit(1)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"lnb/pkg/lnb"
+)
+
+// handleDryRun runs a command with --dry-run: its file changes are planned
+// instead of made, and listed once it is done
+func handleDryRun(args []string) {
+	if strings.EqualFold(args[0], "sync") {
+		fmt.Println("Error: sync does not support --dry-run; it changes a git repository as well as lnb's files.")
+		fmt.Println("Use 'lnb sync status' to see where the local and remote entries stand.")
+		os.Exit(1)
+	}
+
+	fmt.Println("Dry run: nothing is changed.")
+	planned, _ := lnb.Plan(func() error {
+		runCommand(args)
+		return nil
+	})
+	printPlan(planned)
+}
+
+// printPlan lists planned changes. The config file is often saved several
+// times by one command, so only its last write is listed.
+func printPlan(planned []*lnb.Planned) {
+	lastConfig := -1
+	for i, change := range planned {
+		if change.Role == lnb.RoleConfig && change.Action == lnb.PlanWrite {
+			lastConfig = i
+		}
+	}
+
+	var lines []string
+	for i, change := range planned {
+		if change.Role == lnb.RoleConfig && change.Action == lnb.PlanWrite && i != lastConfig {
+			continue
+		}
+		lines = append(lines, describePlanned(change)...)
+	}
+
+	if len(lines) == 0 {
+		fmt.Println("\nNo files would change.")
+		return
+	}
+	fmt.Println("\nPlanned changes:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// describePlanned renders one planned change as lines of the plan, with the
+// content of a written script, wrapper or startup file below it
+func describePlanned(change *lnb.Planned) []string {
+	line := func(what, detail string) []string {
+		return []string{fmt.Sprintf("  %-17s %s", what, detail)}
+	}
+
+	switch change.Role {
+	case lnb.RoleConfig:
+		if change.Action == lnb.PlanWrite {
+			return line("update config", change.Path)
+		}
+	case lnb.RoleJournal:
+		return line("record history", change.Path)
+	case lnb.RoleSnapshot:
+		if change.Action == lnb.PlanWrite {
+			return line("snapshot config", change.Path)
+		}
+		if change.Action == lnb.PlanRemove {
+			return line("prune snapshot", change.Path)
+		}
+	case lnb.RoleShellRC:
+		if change.Action == lnb.PlanAddPath {
+			return line("modify PATH", "add "+change.Path+" to the user PATH")
+		}
+		if change.Action == lnb.PlanWrite {
+			return append(line("modify PATH", change.Path), planContent(change)...)
+		}
+	}
+
+	switch change.Action {
+	case lnb.PlanMkdir:
+		return line("create directory", change.Path)
+	case lnb.PlanSymlink:
+		return line("create symlink", change.Path+" → "+change.Target)
+	case lnb.PlanRemove:
+		return line("remove", change.Path)
+	case lnb.PlanRename:
+		return line("rename", change.Path+" → "+change.Target)
+	case lnb.PlanAppend:
+		return append(line("append to", change.Path), planContent(change)...)
+	}
+	return append(line("write", fmt.Sprintf("%s (mode %04o)", change.Path, change.Mode.Perm())), planContent(change)...)
+}
+
+// planContent indents what a change writes, or how it changes a file that
+// exists, or sums it up when it is not text
+func planContent(change *lnb.Planned) []string {
+	content := change.Content
+	if diff := change.Diff(); diff != "" {
+		content = diff
+	}
+	if !utf8.ValidString(content) || strings.IndexByte(content, 0) >= 0 {
+		return []string{fmt.Sprintf("      (%d bytes of binary data)", len(content))}
+	}
+	var lines []string
+	for _, text := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		lines = append(lines, "      "+text)
+	}
+	return lines
+}
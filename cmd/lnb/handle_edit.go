@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"lnb/pkg/lnb"
+)
+
+// handleEditCommand opens an alias in $EDITOR and applies the result
+func handleEditCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Error: edit requires an alias name.")
+		fmt.Println("Usage: lnb edit <name>")
+		os.Exit(1)
+	}
+
+	client := getClient()
+	spec, err := client.Spec(args[0])
+	if err != nil {
+		exitWithError(err)
+	}
+	original, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		exitWithError(err)
+	}
+	original = append(original, '\n')
+
+	file, err := os.CreateTemp("", "lnb-"+filepath.Base(args[0])+"-*.json")
+	if err != nil {
+		exitWithError(err)
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	text := original
+	for {
+		if err := os.WriteFile(path, text, 0600); err != nil {
+			exitWithError(err)
+		}
+		if err := runEditor(path); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if text, err = os.ReadFile(path); err != nil {
+			exitWithError(err)
+		}
+		if bytes.Equal(bytes.TrimSpace(text), bytes.TrimSpace(original)) {
+			fmt.Printf("No changes to '%s'\n", args[0])
+			return
+		}
+
+		result, err := applyEdit(client, args[0], text)
+		if err == nil {
+			printResult(result)
+			fmt.Printf("Updated alias: %s -> %s\n", result.Name, result.Command)
+			fmt.Printf("✅ Successfully updated alias '%s'\n", result.Name)
+			return
+		}
+
+		fmt.Printf("Error: %v\n", err)
+		if !isInteractive() {
+			os.Exit(1)
+		}
+		fmt.Print("Edit again? [Y/n] ")
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil || strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			os.Exit(1)
+		}
+	}
+}
+
+// applyEdit parses an edited spec and hands it to the client
+func applyEdit(client *lnb.Client, name string, text []byte) (*lnb.Result, error) {
+	var spec lnb.Spec
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("could not read the edited alias: %v", err)
+	}
+	return client.Edit(name, &spec)
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi (notepad on
+// Windows). The variable may hold arguments, e.g. "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	words := strings.Fields(editor)
+	cmd := exec.Command(words[0], append(words[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %v", editor, err)
+	}
+	return nil
+}
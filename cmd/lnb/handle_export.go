@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"lnb/pkg/lnb"
+)
+
+// handleExportCommand writes the entries as a portable bundle:
+// export [--tag <tag>] [--type <type>] [--profile <name>] [--embed] [-o <file>]
+func handleExportCommand(args []string) {
+	output, args, _ := takeFlag(args, "-o")
+	embed, args := takeBoolFlag(args, "--embed")
+	query, _, args := takeQuery(args)
+	if len(args) > 0 {
+		fmt.Println("Usage: lnb export [--tag <tag>] [--type <type>] [--profile <name>] [--embed] [-o <file>]")
+		os.Exit(1)
+	}
+
+	bundle, err := getClient().Export(query, embed)
+	if err != nil {
+		exitWithError(err)
+	}
+	if output == "" || output == "-" {
+		data, err := bundle.Encode()
+		if err != nil {
+			exitWithError(err)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+	if err := bundle.WriteFile(output); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("✅ Exported %d entries to %s\n", len(bundle.Entries), output)
+}
+
+// handleImportCommand recreates the entries of a bundle:
+// import <file>|- [--on-conflict skip|overwrite|rename] [--allow-shadow]
+func handleImportCommand(args []string) {
+	strategy, args, _ := takeFlag(args, "--on-conflict")
+	allowShadow, args := takeBoolFlag(args, "--allow-shadow")
+	args = dropSeparator(args)
+	if len(args) != 1 {
+		fmt.Println("Error: import requires a bundle file made by 'lnb export' ('-' reads stdin).")
+		fmt.Println("Usage: lnb import <file> [--on-conflict skip|overwrite|rename]")
+		os.Exit(1)
+	}
+	conflict, err := lnb.ParseConflict(strategy)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var data []byte
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	var bundle lnb.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		fmt.Printf("Error: %s is not a bundle made by 'lnb export': %v\n", args[0], err)
+		os.Exit(1)
+	}
+
+	client := getClient()
+	client.AllowShadow = allowShadow
+	imported, err := client.Import(&bundle, conflict)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	created, skipped, failed := 0, 0, 0
+	for _, item := range imported {
+		switch {
+		case item.Err != nil:
+			failed++
+			fmt.Printf("Error: '%s': %v\n", item.Name, item.Err)
+		case item.Skipped:
+			skipped++
+			fmt.Printf("Skipped '%s': the name is taken (use --on-conflict overwrite or rename)\n", item.Name)
+		default:
+			created++
+			printResult(item.Result)
+			if item.Result.Name != item.Name {
+				fmt.Printf("Imported '%s' as '%s'\n", item.Name, item.Result.Name)
+			} else {
+				fmt.Printf("Imported '%s'\n", item.Name)
+			}
+		}
+	}
+	fmt.Printf("✅ Imported %d entries (%d skipped, %d failed)\n", created, skipped, failed)
+	if created > 0 {
+		offerShellPath()
+	}
+	if failed > 0 {
+		exitStatus = 1
+	}
+}
+
+// handleSnapshotCommand lists, takes and restores copies of the config:
+// snapshot [list] | create | restore <name>
+func handleSnapshotCommand(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	client := getClient()
+	switch args[0] {
+	case "list":
+		snapshots, err := client.Snapshots()
+		if err != nil {
+			exitWithError(err)
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots yet.")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTAKEN")
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(w, "%s\t%s\n", snapshot.Name, snapshot.Time.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+	case "create":
+		snapshot, err := client.TakeSnapshot()
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("✅ Saved snapshot %s\n", snapshot.Name)
+	case "restore":
+		if len(args) != 2 {
+			fmt.Println("Error: snapshot restore requires a snapshot name; see 'lnb snapshot list'.")
+			fmt.Println("Usage: lnb snapshot restore <name>")
+			os.Exit(1)
+		}
+		backup, results, err := client.RestoreSnapshot(args[1])
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, result := range results {
+			printResult(result)
+		}
+		fmt.Printf("✅ Restored snapshot %s (%d entries); the config it replaced is snapshot %s\n", args[1], len(results), backup.Name)
+	default:
+		fmt.Printf("Error: Unknown snapshot command '%s'\n", args[0])
+		fmt.Println("Usage: lnb snapshot [list] | create | restore <name>")
+		os.Exit(1)
+	}
+}
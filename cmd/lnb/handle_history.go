@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"lnb/pkg/lnb"
+)
+
+// handleHistoryCommand lists the journaled operations, newest last:
+// history [--limit <n>]
+func handleHistoryCommand(args []string) {
+	limitText, args, hasLimit := takeFlag(args, "--limit")
+	limit, err := strconv.Atoi(limitText)
+	if len(args) > 0 || (hasLimit && (err != nil || limit < 1)) {
+		fmt.Println("Usage: lnb history [--limit <n>]")
+		os.Exit(1)
+	}
+
+	records, err := getClient().History()
+	if err != nil {
+		exitWithError(err)
+	}
+	if len(records) == 0 {
+		fmt.Println("No operations recorded yet.")
+		return
+	}
+
+	undoneBy := make(map[int]int)
+	for _, record := range records {
+		if record.Undoes != 0 {
+			undoneBy[record.Undoes] = record.ID
+		}
+	}
+	if hasLimit && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tOPERATION\tNAMES\t")
+	for _, record := range records {
+		op := record.Op
+		if record.Undoes != 0 {
+			op = fmt.Sprintf("undo #%d", record.Undoes)
+		}
+		note := ""
+		if id, undone := undoneBy[record.ID]; undone {
+			note = fmt.Sprintf("(undone by #%d)", id)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", record.ID, record.Time.Local().Format("2006-01-02 15:04"), op, strings.Join(record.Names(), ", "), note)
+	}
+	w.Flush()
+}
+
+// handleUndoCommand reverses the latest operation, or the one with the given id:
+// undo [<id>]
+func handleUndoCommand(args []string) {
+	id := 0
+	if len(args) == 1 {
+		var err error
+		if id, err = strconv.Atoi(strings.TrimPrefix(args[0], "#")); err != nil || id < 1 {
+			fmt.Printf("Error: '%s' is not an operation id; see 'lnb history'.\n", args[0])
+			os.Exit(1)
+		}
+	} else if len(args) > 1 {
+		fmt.Println("Usage: lnb undo [<id>]")
+		os.Exit(1)
+	}
+
+	record, results, err := getClient().Undo(id)
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, result := range results {
+		printResult(result)
+	}
+	fmt.Printf("✅ Undid #%d (%s)\n", record.ID, describeRecord(record))
+}
+
+// describeRecord summarizes an operation, e.g. "alias deploy"
+func describeRecord(record *lnb.Record) string {
+	op := record.Op
+	if record.Undoes != 0 {
+		op = fmt.Sprintf("undo of #%d:", record.Undoes)
+	}
+	return op + " " + strings.Join(record.Names(), ", ")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lnb/pkg/lnb"
+)
+
+// handleInfoCommand shows everything lnb knows about one entry
+func handleInfoCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Error: info requires a name.")
+		fmt.Println("Usage: lnb info <name>")
+		os.Exit(1)
+	}
+
+	info, err := getClient().Info(args[0])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	fmt.Printf("  %s\n", info.Entry.Name)
+	displayEntry(info.Entry)
+	if info.Entry.Literal {
+		fmt.Println("    Literal:   yes")
+	}
+	for _, file := range info.Files {
+		fmt.Printf("    Wrapper:   %s\n", describeFile(file))
+	}
+
+	fmt.Println()
+	switch {
+	case len(info.Resolution.Matches) == 0:
+		fmt.Printf("  ⚠️  '%s' is not found on PATH\n", info.Entry.Name)
+	case !info.Resolution.Matches[0].Managed:
+		fmt.Printf("  ⚠️  shadowed by %s\n", strings.Join(info.Resolution.ShadowedBy(), ", "))
+	default:
+		fmt.Printf("  PATH:      %s is found first\n", info.Resolution.Matches[0].Path)
+		for _, path := range info.Resolution.Shadows() {
+			fmt.Printf("  Shadows:   %s\n", path)
+		}
+	}
+	for _, problem := range info.Problems {
+		fmt.Printf("  ⚠️  %s\n", problem)
+	}
+	for _, file := range info.Files {
+		if file.Stale() {
+			fmt.Printf("  ⚠️  %s differs from what lnb generates today (lnb cat --diff %s)\n", file.Path, info.Entry.Name)
+		}
+	}
+	if info.Healthy() {
+		fmt.Println("  ✅ Healthy")
+	}
+}
+
+// describeFile says what kind of file lnb generated
+func describeFile(file *lnb.File) string {
+	switch {
+	case !file.Exists:
+		return file.Path + " (missing)"
+	case file.Link != "":
+		return file.Path + " (symlink to " + file.Link + ")"
+	case file.Binary:
+		return file.Path + " (binary file)"
+	}
+
+	kind := "sh script"
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".bat", ".cmd":
+		kind = "batch file"
+	case ".ps1":
+		kind = "PowerShell script"
+	case ".desktop":
+		kind = "menu entry"
+	}
+	return file.Path + " (" + kind + ")"
+}
+
+// handleCatCommand prints the files lnb generated for an entry, or with
+// --diff how they differ from what lnb would generate today
+func handleCatCommand(args []string) {
+	diff, args := takeBoolFlag(args, "--diff")
+	if len(args) != 1 {
+		fmt.Println("Error: cat requires a name.")
+		fmt.Println("Usage: lnb cat [--diff] <name>")
+		os.Exit(1)
+	}
+
+	files, err := getClient().Files(args[0])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if diff {
+		stale := false
+		for _, file := range files {
+			if text := file.Diff(); text != "" {
+				fmt.Print(text)
+				stale = true
+			}
+		}
+		if stale {
+			os.Exit(1)
+		}
+		fmt.Printf("✅ '%s' matches what lnb generates today\n", args[0])
+		return
+	}
+
+	for i, file := range files {
+		if len(files) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("==> %s <==\n", file.Path)
+		}
+		switch {
+		case !file.Exists:
+			fmt.Printf("(%s is missing)\n", file.Path)
+		case file.Link != "":
+			fmt.Printf("%s -> %s\n", file.Path, file.Link)
+		case file.Binary:
+			fmt.Printf("(binary file, %d bytes)\n", len(file.Content))
+		default:
+			fmt.Print(file.Content)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"lnb/pkg/lnb"
+)
+
+// handleListCommand lists installed binaries and aliases, optionally
+// filtered by a name pattern and the query flags
+func handleListCommand(args []string) {
+	allProfiles, args := takeBoolFlag(args, "--all-profiles")
+	query, compact, args := takeQuery(args)
+	if len(args) > 1 {
+		fmt.Println("Error: list takes at most one name pattern.")
+		fmt.Println("Usage: lnb list [<pattern>] [--type <type>] [--tag <tag>] [--regex <re>] [--sort name|installed|used] [--compact] [--profile <name> | --all-profiles]")
+		os.Exit(1)
+	}
+	if len(args) == 1 {
+		query.Glob = args[0]
+	}
+	if allProfiles {
+		showProfiles(query, compact)
+		return
+	}
+	showQuery(query, compact)
+}
+
+// showProfiles prints the entries matching query in every profile
+func showProfiles(query lnb.Query, compact bool) {
+	profiles, err := getClient().Profiles()
+	if err != nil {
+		exitWithError(err)
+	}
+	for i, profile := range profiles {
+		if i > 0 {
+			fmt.Println()
+		}
+		state := "not in use"
+		if profile.Active {
+			state = "in use"
+		}
+		fmt.Printf("== Profile %s (%s) ==\n", profile.Name, state)
+		query.Profile = profile.Name
+		showQuery(query, compact)
+	}
+}
+
+// handleSearchCommand lists the entries whose name, command or source
+// contains the given text
+func handleSearchCommand(args []string) {
+	query, compact, args := takeQuery(args)
+	if len(args) == 0 {
+		fmt.Println("Error: search requires some text to look for.")
+		fmt.Println("Usage: lnb search <text> [--type <type>] [--tag <tag>] [--sort name|installed|used] [--compact]")
+		os.Exit(1)
+	}
+	query.Text = strings.Join(args, " ")
+	showQuery(query, compact)
+}
+
+// takeQuery removes the filter and display flags shared by list and search
+func takeQuery(args []string) (lnb.Query, bool, []string) {
+	var query lnb.Query
+	query.Type, args, _ = takeFlag(args, "--type")
+	query.Tag, args, _ = takeFlag(args, "--tag")
+	query.Pattern, args, _ = takeFlag(args, "--regex")
+	query.Sort, args, _ = takeFlag(args, "--sort")
+	query.Profile, args, _ = takeFlag(args, "--profile")
+	compact, args := takeBoolFlag(args, "--compact")
+	return query, compact, dropSeparator(args)
+}
+
+// showQuery prints the entries matching query
+func showQuery(query lnb.Query, compact bool) {
+	entries, err := getClient().Find(query)
+	if err != nil {
+		exitWithError(err)
+	}
+	if len(entries) == 0 && query != (lnb.Query{Sort: query.Sort, Profile: query.Profile}) {
+		fmt.Println("No entries match.")
+		return
+	}
+
+	if compact {
+		displayTable(entries, query.Sort == lnb.SortUsed)
+		return
+	}
+	displayEntries(entries)
+}
+
+// displayTable prints one line per entry
+func displayTable(entries []*lnb.Entry, showUsed bool) {
+	if len(entries) == 0 {
+		fmt.Println("No binaries or aliases installed by LNB.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "NAME\tTYPE\tINSTALLED"
+	if showUsed {
+		header += "\tLAST USED"
+	}
+	fmt.Fprintln(w, header+"\tRUNS")
+	for _, entry := range entries {
+		line := entry.Name + "\t" + string(entry.Kind) + "\t" + entry.InstalledAt.Format("2006-01-02")
+		if showUsed {
+			used := "-"
+			if at, ok := lnb.LastUsed(entry); ok {
+				used = at.Format("2006-01-02")
+			}
+			line += "\t" + used
+		}
+		fmt.Fprintln(w, line+"\t"+runs(entry))
+	}
+	w.Flush()
+}
+
+// runs summarises what an entry runs in a single line
+func runs(entry *lnb.Entry) string {
+	switch {
+	case entry.Container != nil:
+		return entry.Container.Image
+	case entry.Command != nil:
+		return entry.CommandFor(runtime.GOOS).String()
+	}
+	return entry.SourcePath
+}
+
+// displayEntries displays the list of installed binaries and aliases
+func displayEntries(entries []*lnb.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("No binaries or aliases installed by LNB.")
+		return
+	}
+
+	fmt.Printf("Binaries and aliases installed by LNB (%d):\n\n", len(entries))
+	for _, entry := range entries {
+		fmt.Printf("  %s\n", entry.Name)
+		displayEntry(entry)
+		fmt.Println()
+	}
+}
+
+// displayEntry prints the recorded details of one entry, indented under its name
+func displayEntry(entry *lnb.Entry) {
+	fmt.Printf("    Type:      %s\n", entry.Kind)
+	if entry.Description != "" {
+		fmt.Printf("    About:     %s\n", entry.Description)
+	}
+	if len(entry.Tags) > 0 {
+		fmt.Printf("    Tags:      %s\n", strings.Join(entry.Tags, ", "))
+	}
+	if entry.Command != nil {
+		fmt.Printf("    Command:   %s\n", entry.Command)
+		if entry.Command.Dir != "" {
+			fmt.Printf("    Dir:       %s\n", entry.Command.Dir)
+		}
+		for _, key := range entry.Command.EnvKeys() {
+			fmt.Printf("    Env:       %s=%s\n", key, entry.Command.Env[key])
+		}
+	}
+	for _, goos := range lnb.VariantOSes {
+		if variant, ok := entry.Variants[goos]; ok {
+			fmt.Printf("    %-10s %s\n", goos+":", variant)
+		}
+	}
+	if entry.Fallback != nil {
+		fmt.Printf("    Fallback:  %s\n", entry.Fallback)
+	}
+	if entry.Container != nil {
+		fmt.Printf("    Image:     %s\n", entry.Container.Image)
+		fmt.Printf("    Runtime:   %s\n", entry.Container.Runtime)
+	}
+	if entry.SourcePath != "" {
+		fmt.Printf("    Source:    %s\n", entry.SourcePath)
+	}
+	fmt.Printf("    Target:    %s\n", entry.TargetPath)
+	for _, extra := range entry.ExtraTargets {
+		fmt.Printf("    Also:      %s\n", extra)
+	}
+	fmt.Printf("    Installed: %s\n", entry.InstalledAt.Format("2006-01-02 15:04:05"))
+	if entry.UpdatedAt != nil {
+		fmt.Printf("    Updated:   %s\n", entry.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
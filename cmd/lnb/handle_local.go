@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"lnb/pkg/lnb"
+)
+
+// handleLocalAlias defines an alias in the current project's .lnb.yaml
+func handleLocalAlias(aliasName, aliasCommand string, allowShadow bool, opts lnb.Options) {
+	dir, err := os.Getwd()
+	if err != nil {
+		exitWithError(err)
+	}
+	client := getClient()
+	client.AllowShadow = allowShadow
+	client.Options = opts
+
+	result, path, err := client.LocalAlias(aliasName, aliasCommand, dir)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	printResult(result)
+	fmt.Printf("Defined local alias: %s -> %s in %s\n", result.Name, result.Command, path)
+	fmt.Printf("✅ '%s' runs '%s' anywhere under this project\n", result.Name, result.Command)
+	offerShellPath()
+}
+
+// handleRemoveLocalAlias removes an alias from the nearest .lnb.yaml defining it
+func handleRemoveLocalAlias(aliasName string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		exitWithError(err)
+	}
+	path, err := getClient().RemoveLocalAlias(aliasName, dir)
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("✅ Removed '%s' from %s\n", aliasName, path)
+}
+
+// handleRunCommand runs what the local alias called name means in the
+// current directory. The wrappers of local aliases call it.
+func handleRunCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: run requires the name of a local alias.")
+		fmt.Println("Usage: lnb run <name> [<args>...]")
+		os.Exit(1)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		exitWithError(err)
+	}
+	local, err := getClient().Local(args[0], dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lnb: %v\n", err)
+		os.Exit(127)
+	}
+
+	cmd := local.Cmd(args[1:])
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "lnb: %s: %v\n", args[0], err)
+		os.Exit(127)
+	}
+}
+
+// handleTrustCommand allows the local aliases of the nearest .lnb.yaml to run
+func handleTrustCommand(args []string) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	path, err := getClient().Trust(dir)
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("✅ Local aliases from %s may run\n", path)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// handleMoveCommand handles mv <old> <new>
+func handleMoveCommand(args []string) {
+	allowShadow, args := takeBoolFlag(args, "--allow-shadow")
+	if len(args) != 2 {
+		fmt.Println("Error: mv requires the current and the new name.")
+		fmt.Println("Usage: lnb mv <old> <new>")
+		os.Exit(1)
+	}
+
+	client := getClient()
+	client.AllowShadow = allowShadow
+
+	result, err := client.Move(args[0], args[1])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	printResult(result)
+	fmt.Printf("Moved: %s -> %s\n", args[0], result.TargetPath)
+	fmt.Printf("✅ Successfully renamed '%s' to '%s'\n", args[0], result.Name)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// handleProfileCommand manages profiles: named sets of entries of which one
+// is in use at a time
+func handleProfileCommand(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		handleProfileList()
+	case "create":
+		binDir, rest, _ := takeFlag(args[1:], "--bin-dir")
+		if len(rest) != 1 {
+			fmt.Println("Error: profile create requires a name.")
+			fmt.Println("Usage: lnb profile create <name> [--bin-dir <dir>]")
+			os.Exit(1)
+		}
+		if err := getClient().CreateProfile(rest[0], binDir); err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("✅ Created profile '%s'; switch to it with 'lnb profile use %s'\n", rest[0], rest[0])
+	case "use":
+		if len(args) != 2 {
+			fmt.Println("Error: profile use requires a name.")
+			fmt.Println("Usage: lnb profile use <name>")
+			os.Exit(1)
+		}
+		handleProfileUse(args[1])
+	case "bin-dir":
+		handleProfileBinDir(args[1:])
+	case "delete":
+		if len(args) != 2 {
+			fmt.Println("Error: profile delete requires a name.")
+			fmt.Println("Usage: lnb profile delete <name>")
+			os.Exit(1)
+		}
+		if err := getClient().DeleteProfile(args[1]); err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("✅ Deleted profile '%s'\n", args[1])
+	default:
+		fmt.Printf("Error: Unknown profile command '%s'\n", args[0])
+		fmt.Println("Usage: lnb profile [list] | create <name> [--bin-dir <dir>] | use <name> | bin-dir <name> [<dir>] | delete <name>")
+		os.Exit(1)
+	}
+}
+
+// handleProfileList prints every profile, marking the one in use
+func handleProfileList() {
+	profiles, err := getClient().Profiles()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PROFILE\tENTRIES\tBIN DIR")
+	for _, profile := range profiles {
+		marker := " "
+		if profile.Active {
+			marker = "*"
+		}
+		binDir := profile.BinDir
+		if binDir == "" {
+			binDir = "(usual)"
+		}
+		fmt.Fprintf(w, "%s %s\t%d\t%s\n", marker, profile.Name, profile.Entries, binDir)
+	}
+	w.Flush()
+}
+
+// handleProfileUse switches to another profile
+func handleProfileUse(name string) {
+	client := getClient()
+	active, err := client.ActiveProfile()
+	if err != nil {
+		exitWithError(err)
+	}
+	if name == active {
+		fmt.Printf("Profile '%s' is already in use.\n", name)
+		return
+	}
+
+	results, err := client.UseProfile(name)
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, result := range results {
+		printResult(result)
+	}
+	fmt.Printf("✅ Switched from profile '%s' to '%s' (%d entries in %s)\n", active, name, len(results), client.BinDir())
+	offerShellPath()
+}
+
+// handleProfileBinDir shows or changes where a profile's entries are created
+func handleProfileBinDir(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Println("Error: profile bin-dir requires a profile name.")
+		fmt.Println("Usage: lnb profile bin-dir <name> [<dir>]")
+		os.Exit(1)
+	}
+
+	client := getClient()
+	if len(args) == 1 {
+		profiles, err := client.Profiles()
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, profile := range profiles {
+			if profile.Name == args[0] {
+				if profile.BinDir == "" {
+					fmt.Println("(usual bin directory)")
+				} else {
+					fmt.Println(profile.BinDir)
+				}
+				return
+			}
+		}
+		fmt.Printf("Error: there is no profile called '%s'\n", args[0])
+		os.Exit(1)
+	}
+
+	if err := client.SetProfileBinDir(args[0], args[1]); err != nil {
+		exitWithError(err)
+	}
+	if args[1] == "" {
+		fmt.Printf("✅ Profile '%s' now uses the usual bin directory\n", args[0])
+	} else {
+		fmt.Printf("✅ Profile '%s' now creates its entries in %s\n", args[0], args[1])
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// handleSelfCommand handles lnb's own setup: bin directory and shell PATH integration
+func handleSelfCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: self command requires a subcommand.")
+		fmt.Println("Usage: lnb self bin-dir [<dir>] | lnb self path [--add] | lnb self uninstall")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "bin-dir":
+		handleSelfBinDir(args[1:])
+	case "path":
+		add, _ := takeBoolFlag(args[1:], "--add")
+		handleSelfPath(add)
+	case "uninstall":
+		handleSelfUninstall()
+	default:
+		fmt.Printf("Error: Unknown self command '%s'\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// handleSelfBinDir shows or changes the bin directory
+func handleSelfBinDir(args []string) {
+	client := getClient()
+	if len(args) == 0 {
+		fmt.Println(client.BinDir())
+		return
+	}
+
+	if err := client.SetBinDir(args[0]); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("✅ New entries will be created in %s\n", client.BinDir())
+	offerShellPath()
+}
+
+// handleSelfPath shows, and with add fixes, the PATH setup of each shell
+func handleSelfPath(add bool) {
+	client := getClient()
+	if runtime.GOOS == "windows" {
+		fmt.Printf("Bin directory: %s\n", client.BinDir())
+		fmt.Println("On Windows lnb adds the bin directory to your user PATH when it installs something.")
+		return
+	}
+
+	statuses, err := client.ShellPath()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	fmt.Printf("Bin directory: %s\n\n", client.BinDir())
+	for _, status := range statuses {
+		state := "not on PATH"
+		if status.HasBlock {
+			state = "on PATH (lnb block)"
+		} else if status.Mentioned {
+			state = "on PATH"
+		}
+		fmt.Printf("  %-5s %s: %s\n", status.Name, status.RCFile, state)
+	}
+
+	if !add {
+		return
+	}
+
+	changed, err := client.AddShellPath()
+	if err != nil {
+		exitWithError(err)
+	}
+	printShellPathChanges(changed)
+}
+
+// handleSelfUninstall removes everything lnb added to shell startup files
+func handleSelfUninstall() {
+	client := getClient()
+	if runtime.GOOS != "windows" {
+		changed, err := client.RemoveShellPath()
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, file := range changed {
+			fmt.Printf("Removed lnb PATH block from %s\n", file)
+		}
+		if len(changed) == 0 {
+			fmt.Println("No lnb PATH blocks found in shell startup files.")
+		}
+	}
+
+	fmt.Println("Entries created by lnb are left in place; use 'lnb list' and 'lnb remove' to clean them up.")
+}
+
+// offerShellPath offers to put the bin directory on PATH in shell startup
+// files when it is not on PATH yet. It only asks on an interactive terminal.
+func offerShellPath() {
+	client := getClient()
+	if runtime.GOOS == "windows" || client.InPath() {
+		return
+	}
+
+	statuses, err := client.ShellPath()
+	if err != nil {
+		return
+	}
+
+	var missing []string
+	for _, status := range statuses {
+		if !status.OnPath() {
+			missing = append(missing, status.RCFile)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("🔧 %s is set up in your shell startup files; open a new terminal to use it.\n", client.BinDir())
+		return
+	}
+
+	fmt.Printf("⚠️  %s is not on your PATH.\n", client.BinDir())
+	if !isInteractive() {
+		fmt.Println("Run 'lnb self path --add' to add it to " + strings.Join(missing, ", "))
+		return
+	}
+
+	fmt.Printf("Add it to %s? [y/N] ", strings.Join(missing, ", "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+		return
+	}
+
+	changed, err := client.AddShellPath()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	printShellPathChanges(changed)
+}
+
+// printShellPathChanges reports the rc files that gained the lnb PATH block
+func printShellPathChanges(changed []string) {
+	for _, file := range changed {
+		fmt.Printf("🔧 Added lnb PATH block to %s\n", file)
+	}
+	if len(changed) > 0 {
+		fmt.Println("Open a new terminal to use the new PATH.")
+	}
+}
+
+// isInteractive reports whether stdin is a terminal
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"lnb/pkg/lnb"
+)
+
+// handleSyncCommand keeps the entries in a git repository shared between
+// machines: sync init <git-remote> | pull | push [--prefer newer|local|remote]
+func handleSyncCommand(args []string) {
+	preferText, args, _ := takeFlag(args, "--prefer")
+	allowShadow, args := takeBoolFlag(args, "--allow-shadow")
+	prefer, err := lnb.ParsePrefer(preferText)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		args = []string{"status"}
+	}
+
+	client := getClient()
+	client.AllowShadow = allowShadow
+	switch args[0] {
+	case "init":
+		if len(args) != 2 {
+			fmt.Println("Error: sync init requires a git remote.")
+			fmt.Println("Usage: lnb sync init <git-remote>")
+			os.Exit(1)
+		}
+		dir, err := client.SyncInit(args[1])
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("✅ Syncing with %s (cloned to %s)\n", args[1], dir)
+		fmt.Println("Run 'lnb sync pull' to bring its entries here, or 'lnb sync push' to share yours.")
+	case "status":
+		remote, err := client.SyncRemote()
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Syncing with %s\n", remote)
+	case "pull":
+		synced, err := client.SyncPull(prefer)
+		if err != nil {
+			exitWithError(err)
+		}
+		failed := printSynced(synced)
+		fmt.Println("✅ Pulled from the sync repository")
+		if failed {
+			os.Exit(1)
+		}
+	case "push":
+		synced, pushed, err := client.SyncPush(prefer)
+		failed := printSynced(synced)
+		if err != nil {
+			exitWithError(err)
+		}
+		if pushed {
+			fmt.Println("✅ Pushed to the sync repository")
+		} else {
+			fmt.Println("✅ The sync repository is up to date")
+		}
+		if failed {
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Error: Unknown sync command '%s'\n", args[0])
+		fmt.Println("Usage: lnb sync init <git-remote> | pull | push [--prefer newer|local|remote]")
+		os.Exit(1)
+	}
+}
+
+// printSynced reports what a sync did with each entry and whether any entry
+// could not be applied
+func printSynced(synced []*lnb.Synced) bool {
+	failed := false
+	for _, item := range synced {
+		if item.Err != nil {
+			failed = true
+			fmt.Printf("Error: '%s': %v\n", item.Name, item.Err)
+			continue
+		}
+		if item.Result != nil {
+			printResult(item.Result)
+		}
+		conflict := ""
+		if item.Conflict {
+			conflict = " (changed on both sides)"
+		}
+		switch item.Action {
+		case lnb.SyncAdded:
+			fmt.Printf("Added '%s'%s\n", item.Name, conflict)
+		case lnb.SyncUpdated:
+			fmt.Printf("Updated '%s'%s\n", item.Name, conflict)
+		case lnb.SyncRemoved:
+			fmt.Printf("Removed '%s'%s\n", item.Name, conflict)
+		case lnb.SyncLocal:
+			if item.Conflict {
+				fmt.Printf("Kept the local version of '%s'%s\n", item.Name, conflict)
+			} else {
+				fmt.Printf("Local change to '%s'\n", item.Name)
+			}
+		case lnb.SyncSkipped:
+			fmt.Printf("Skipped '%s': %s\n", item.Name, item.Note)
+		}
+	}
+	return failed
+}
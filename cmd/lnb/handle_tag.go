@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// handleTagCommand shows or changes the tags and description of an entry:
+// tag <name> [<tag>...] [--remove <tag>]... [--desc <text>]
+func handleTagCommand(args []string) {
+	remove, args := takeRepeatedFlag(args, "--remove")
+	desc, args, setDesc := takeFlag(args, "--desc")
+	args = dropSeparator(args)
+	if len(args) < 1 {
+		fmt.Println("Error: tag requires a name.")
+		fmt.Println("Usage: lnb tag <name> [<tag>...] [--remove <tag>] [--desc <text>]")
+		os.Exit(1)
+	}
+
+	client := getClient()
+	name, add := args[0], args[1:]
+	if len(add) > 0 || len(remove) > 0 {
+		if _, err := client.Tag(name, add, remove); err != nil {
+			exitWithError(err)
+		}
+	}
+	if setDesc {
+		if _, err := client.Describe(name, desc); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	info, err := client.Info(name)
+	if err != nil {
+		exitWithError(err)
+	}
+	tags := "(none)"
+	if len(info.Entry.Tags) > 0 {
+		tags = strings.Join(info.Entry.Tags, ", ")
+	}
+	fmt.Printf("  %s\n", info.Entry.Name)
+	fmt.Printf("    Tags:      %s\n", tags)
+	if info.Entry.Description != "" {
+		fmt.Printf("    About:     %s\n", info.Entry.Description)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// handleWhichCommand shows every executable a name resolves to on PATH
+func handleWhichCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: which command requires a name.")
+		fmt.Println("Usage: lnb which <name>")
+		os.Exit(1)
+	}
+
+	resolution := getClient().Which(args[0])
+	if len(resolution.Matches) == 0 {
+		fmt.Printf("'%s' is not found on PATH\n", resolution.Name)
+		if !resolution.BinDirInPath() {
+			fmt.Printf("Note: the lnb bin directory %s is not on PATH\n", resolution.BinDir)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("Resolution of '%s' on PATH:\n\n", resolution.Name)
+	for i, match := range resolution.Matches {
+		status := "shadowed"
+		if i == 0 {
+			status = "runs"
+		}
+		owner := ""
+		if match.Managed {
+			owner = " (lnb)"
+		}
+		fmt.Printf("  %d. %s%s  [%s]\n", i+1, match.Path, owner, status)
+	}
+
+	if !resolution.BinDirInPath() {
+		fmt.Printf("\nNote: the lnb bin directory %s is not on PATH\n", resolution.BinDir)
+	}
+}
@@ -13,6 +13,10 @@ var (
 	date    = "unknown"
 )
 
+// exitStatus is what lnb exits with once a command that only partly
+// succeeded is done
+var exitStatus int
+
 func showHelp() {
 	fmt.Printf(`LNB v%s - Cross-Platform Alias Manager
 
@@ -21,20 +25,124 @@ USAGE:
 
 COMMANDS:
     alias <name> "<command>"    Create an alias for a command
+    alias --update <name> "<command>"
+                                Change the command of an existing alias
+    edit <name>                 Change an alias in $EDITOR
+    alias --local <name> "<command>"
+                                Define an alias in this project's .lnb.yaml
     unalias <name>              Remove an alias
-    <file-path>                 Make a binary globally accessible
+    unalias --local <name>      Remove an alias from the nearest .lnb.yaml
+    run <name> [<args>...]      Run a local alias here (what its wrapper does)
+    trust [<dir>]               Allow the local aliases of a .lnb.yaml to run
+    container-alias <name> <image> [-- <cmd>...]
+                                Run an image with docker or podman in the
+                                current directory
+    <file-path> [--as <name>]   Make a binary globally accessible
     remove <name>               Remove a binary or alias
-    list                        List everything
+    remove --tag <tag>          Remove every entry with a tag
+    mv <old> <new>              Rename a binary or alias
+    tag <name> [<tag>...] [--remove <tag>] [--desc <text>]
+                                Show or change an entry's tags and description
+    list [<pattern>]            List everything, or the names matching a pattern
+    search <text>               List entries whose name, command or source contains text
+    info <name>                 Show one entry, its wrapper, health and PATH status
+    cat [--diff] <name>         Print the generated wrapper, or how it differs from
+                                what lnb would generate today
+    which <name>                Show every command a name resolves to on PATH
+    doctor                      Check entries and PATH for problems
+    history [--limit <n>]       List the operations lnb has recorded
+    undo [<id>]                 Reverse the latest operation, or the one with this id
+    export [--tag <tag>] [--embed] [-o <file>]
+                                Write entries as a portable bundle (to stdout by default)
+    import <file> [--on-conflict skip|overwrite|rename]
+                                Recreate the entries of a bundle
+    snapshot [list] | create | restore <name>
+                                List, take or restore copies of the config
+    sync init <git-remote>      Share entries with other machines through a git repository
+    sync pull | push [--prefer newer|local|remote]
+                                Bring its changes here, or also send yours
+    profile [list]              List profiles; * marks the one in use
+    profile create <name> [--bin-dir <dir>]
+                                Add an empty profile, optionally with its own bin directory
+    profile use <name>          Swap the entries of the profile in use for another's
+    profile bin-dir <name> [<dir>]
+                                Show or change where a profile's entries are created
+    profile delete <name>       Delete a profile that is not in use, with its entries
+    self bin-dir [<dir>]        Show or change where lnb creates commands
+    self path [--add]           Check, or add, the bin directory in shell startup files
+    self uninstall              Remove lnb's PATH block from shell startup files
     help                        Show this help
     version                     Show version
 
 EXAMPLES:
     lnb alias deploy "docker run --rm -v $(pwd):/app deploy-image"
     lnb alias logs "tail -f /var/log/nginx/access.log"  
+    lnb alias --update logs "tail -f /var/log/nginx/error.log"
+                                Point an alias at a new command
     lnb ./mybinary              Make binary globally accessible
+    lnb ./my-tool-v2 --as tool  Install a binary under another name
+    lnb ./App.AppImage --as app --desktop
+                                Install an AppImage with a menu entry
+    lnb container-alias node node:20 --env NPM_TOKEN -- node
+                                Run node from an image on the current directory
     lnb remove mybinary         Remove binary
+    lnb mv mybinary mytool      Rename a binary or alias
+    lnb alias tf terraform --tag infra --desc "Terraform for the infra repo"
+                                Create a tagged, described alias
     lnb unalias deploy          Remove alias
+    lnb alias clip "xclip -selection clipboard" --darwin pbcopy --windows clip
+                                One alias with a different command per OS
+    lnb alias --local build "make -C backend"
+                                Define build for this project only
     lnb list                    Show everything
+    lnb list 'git-*' --compact  One line per entry whose name starts with git-
+    lnb search docker           Find the aliases that run docker
+    lnb profile create clienta  Start a separate set of aliases
+    lnb profile use clienta     Switch to it
+    lnb undo                    Take back the last install, alias, remove or edit
+    lnb export --embed > setup.json
+                                Carry everything, binaries included, to a new machine
+    lnb import setup.json --on-conflict rename
+    lnb import setup.json --dry-run
+                                See what importing would do before doing it
+
+OPTIONS:
+    --dry-run                   Any command but sync: list the files it would create,
+                                change or remove, and PATH changes, without making them
+    --allow-shadow              Create an alias or binary even if its name is
+                                already found elsewhere on PATH
+    --shim cmd|ps1|both         Windows: write a .cmd/.bat launcher (default),
+                                a PowerShell .ps1 launcher, or both
+    --sh                        Windows: also write an extensionless sh launcher
+                                for Git Bash, MSYS, Cygwin and WSL
+    --desktop                   Linux: also add the binary to the application menu
+                                (uses the AppImage's icon when there is one)
+    --tag <tag>[,<tag>...]      Tag a new entry; may be repeated
+    --desc <text>               Describe what a new entry is for
+    --literal                   alias: store the command exactly as written, without
+                                resolving its program or ./ paths
+    --linux|--darwin|--windows "<command>"
+                                alias: run this command instead on that OS
+
+LIST AND SEARCH:
+    --type <type>               Only binary, alias, script, shim, container or local entries
+    --tag <tag>                 Only entries with this tag
+    --regex <re>                Only names matching a regular expression
+    --sort name|installed|used  Order by name (default), newest install or last run
+                                (last run is the file's access time, so approximate)
+    --compact                   One line per entry
+    --profile <name>            Look in another profile, in use or not
+    --all-profiles              List the entries of every profile
+
+CONTAINER ALIASES:
+    --runtime docker|podman     Container runtime (default: whichever is installed)
+    --env NAME[,NAME...]        Pass host environment variables through
+    --workdir <dir>             Where the current directory is mounted (default /work)
+    --run-arg <arg>             Extra option for 'run', e.g. --run-arg=-p --run-arg=8080:80
+
+NAMES:
+    Names may contain letters, digits, '.', '_', '-' and '+', and are at most
+    64 characters long. Windows device names such as CON or NUL are rejected.
 
 Same command. All platforms.
 Source: https://github.com/muthuishere/lnb
@@ -67,8 +175,10 @@ func isKnownCommand(cmd string) bool {
 		"help", "-h", "--help",
 		"version", "-v", "--version",
 		"list", "ls", "--ls",
-		"alias", "unalias",
-		"install", "remove",
+		"alias", "unalias", "edit", "container-alias",
+		"install", "remove", "mv",
+		"doctor", "which", "self", "info", "cat", "search", "tag", "profile", "run", "trust",
+		"history", "undo", "export", "import", "snapshot", "sync",
 	}
 
 	for _, known := range knownCommands {
@@ -86,13 +196,34 @@ func main() {
 		return
 	}
 
-	command := strings.ToLower(os.Args[1])
-	args := os.Args[2:] // Remaining arguments after command
+	// run passes everything after the name on to the alias, --dry-run included
+	args := os.Args[1:]
+	dryRun := false
+	if !strings.EqualFold(args[0], "run") {
+		dryRun, args = takeBoolFlag(args, "--dry-run")
+	}
+	switch {
+	case len(args) == 0:
+		showHelp()
+	case dryRun:
+		handleDryRun(args)
+	default:
+		runCommand(args)
+	}
+	if exitStatus != 0 {
+		os.Exit(exitStatus)
+	}
+}
+
+// runCommand runs the command args[0] with the rest of args
+func runCommand(all []string) {
+	command := strings.ToLower(all[0])
+	args := all[1:] // Remaining arguments after command
 
 	// Check if first argument is a file path instead of a command
-	if !isKnownCommand(command) && isFilePath(os.Args[1]) {
+	if !isKnownCommand(command) && isFilePath(all[0]) {
 		// Treat as install command with the file path
-		handleBinaryCommand("install", os.Args[1:])
+		handleBinaryCommand("install", all)
 		return
 	}
 
@@ -103,13 +234,51 @@ func main() {
 	case "version", "-v", "--version":
 		showVersion()
 	case "list", "ls", "--ls":
-		handleListCommand()
+		handleListCommand(args)
 	case "alias":
 		handleAliasCommand(args)
 	case "unalias":
 		handleUnaliasCommand(args)
+	case "edit":
+		handleEditCommand(args)
+	case "container-alias":
+		handleContainerAliasCommand(args)
 	case "install", "remove":
 		handleBinaryCommand(command, args)
+	case "mv":
+		handleMoveCommand(args)
+	case "doctor":
+		handleDoctorCommand()
+	case "search":
+		handleSearchCommand(args)
+	case "run":
+		handleRunCommand(args)
+	case "trust":
+		handleTrustCommand(args)
+	case "profile":
+		handleProfileCommand(args)
+	case "tag":
+		handleTagCommand(args)
+	case "history":
+		handleHistoryCommand(args)
+	case "undo":
+		handleUndoCommand(args)
+	case "export":
+		handleExportCommand(args)
+	case "import":
+		handleImportCommand(args)
+	case "snapshot":
+		handleSnapshotCommand(args)
+	case "sync":
+		handleSyncCommand(args)
+	case "info":
+		handleInfoCommand(args)
+	case "cat":
+		handleCatCommand(args)
+	case "which":
+		handleWhichCommand(args)
+	case "self":
+		handleSelfCommand(args)
 	default:
 		fmt.Printf("Error: Unknown command '%s'\n", command)
 		fmt.Println("Use 'lnb help' for usage information.")
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -114,7 +115,6 @@ func setupTestEnvironment(t *testing.T) (projectRoot, testLnbPath, testAssetsDir
 		} else {
 			os.Setenv("LNB_TEST_CONFIG_DIR", originalConfigDir)
 		}
-		cleanupConfig()
 	}
 
 	return root, testLnbPath, testAssetsDir, cleanup
@@ -412,14 +412,21 @@ func TestLnbInstallCommand(t *testing.T) {
 	cmd.Run()
 }
 
-// cleanupConfig removes the LNB config file to start fresh
+// cleanupConfig empties the test config directory to start fresh. The config,
+// journal, snapshots, embedded binaries and sync clone all live there, so the
+// real ~/.lnb is never touched.
 func cleanupConfig() {
-	homeDir, err := os.UserHomeDir()
+	dir := os.Getenv("LNB_TEST_CONFIG_DIR")
+	if dir == "" {
+		return
+	}
+	files, err := os.ReadDir(dir)
 	if err != nil {
 		return
 	}
-	configPath := filepath.Join(homeDir, ".lnb", "config.json")
-	os.Remove(configPath)
+	for _, file := range files {
+		os.RemoveAll(filepath.Join(dir, file.Name()))
+	}
 }
 
 // TestLnbNoArgs tests the help is shown when no arguments are provided
@@ -560,12 +567,18 @@ func TestLnbPathsWithSpaces(t *testing.T) {
 		cleanupFn func()
 	}{
 		{
-			name:     "install_binary_with_spaced_path",
+			name:     "install_binary_with_spaced_name_is_rejected",
 			args:     []string{"install", spacedBinary},
+			wantErr:  true,
+			contains: "name must not contain whitespace",
+		},
+		{
+			name:     "install_binary_with_spaced_path",
+			args:     []string{"install", spacedBinary, "--as", "spacedbinary"},
 			wantErr:  false,
 			contains: "Successfully installed",
 			cleanupFn: func() {
-				exec.Command(testLnbPath, "remove", "spaced binary").Run()
+				exec.Command(testLnbPath, "remove", "spacedbinary").Run()
 			},
 		},
 		{
@@ -732,3 +745,1566 @@ func TestLnbMacAppHandling(t *testing.T) {
 	}
 
 }
+
+// TestLnbDoctor tests that doctor reports an entry whose source has been deleted
+func TestLnbDoctor(t *testing.T) {
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	doctorBinary := filepath.Join(testAssetsDir, "doctorbinary")
+	if err := os.WriteFile(doctorBinary, []byte(testBinaryContent), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+
+	cmd := exec.Command(testLnbPath, "install", doctorBinary)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to install: %v\nOutput: %s", err, string(output))
+	}
+	defer exec.Command(testLnbPath, "remove", "doctorbinary").Run()
+
+	if err := os.Remove(doctorBinary); err != nil {
+		t.Fatalf("Failed to delete test binary: %v", err)
+	}
+
+	cmd = exec.Command(testLnbPath, "doctor")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Errorf("Expected doctor to fail with a missing source. Output: %s", string(output))
+	}
+
+	if !strings.Contains(string(output), "doctorbinary: source "+doctorBinary+" is missing") {
+		t.Errorf("Expected missing source to be reported, got: %s", string(output))
+	}
+}
+
+// TestLnbConfigMigration tests that version 1.0 config files are converted on load
+// and that corrupt files are reported instead of being replaced
+func TestLnbConfigMigration(t *testing.T) {
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	configPath := filepath.Join(os.Getenv("LNB_TEST_CONFIG_DIR"), "config.json")
+	legacyConfig := `{
+  "entries": {
+    "deploy": {
+      "name": "deploy",
+      "source_path": "alias:docker compose up -d",
+      "target_path": "/usr/local/bin/deploy",
+      "installed_at": "2025-01-02T03:04:05Z"
+    },
+    "tool": {
+      "name": "tool",
+      "source_path": "/opt/tool/bin/tool",
+      "target_path": "/usr/local/bin/tool",
+      "installed_at": "2025-01-02T03:04:05Z"
+    }
+  },
+  "version": "1.0"
+}`
+	if err := os.WriteFile(configPath, []byte(legacyConfig), 0644); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	output, err := exec.Command(testLnbPath, "list").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to list: %v\nOutput: %s", err, string(output))
+	}
+
+	outputStr := string(output)
+	if !strings.Contains(outputStr, "Type:      alias") || !strings.Contains(outputStr, "Command:   docker compose up -d") {
+		t.Errorf("Expected migrated alias in list, got: %s", outputStr)
+	}
+	if !strings.Contains(outputStr, "Type:      binary") || !strings.Contains(outputStr, "Source:    /opt/tool/bin/tool") {
+		t.Errorf("Expected migrated binary in list, got: %s", outputStr)
+	}
+
+	if _, err := os.Stat(configPath + ".v1.bak"); err != nil {
+		t.Errorf("Expected backup of legacy config: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read migrated config: %v", err)
+	}
+	if strings.Contains(string(data), "alias:") || !strings.Contains(string(data), `"kind": "alias"`) {
+		t.Errorf("Expected config to be rewritten with entry kinds, got: %s", string(data))
+	}
+	if !strings.Contains(string(data), `"argv": [`) {
+		t.Errorf("Expected alias command to be stored as argv, got: %s", string(data))
+	}
+	if !strings.Contains(string(data), `"version": "2"`) {
+		t.Errorf("Expected the config to be rewritten at version 2, got: %s", string(data))
+	}
+
+	if err := os.WriteFile(configPath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt config: %v", err)
+	}
+
+	output, err = exec.Command(testLnbPath, "list").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "corrupt") {
+		t.Errorf("Expected corrupt config to be reported, got: %v\nOutput: %s", err, string(output))
+	}
+
+	if data, _ := os.ReadFile(configPath); string(data) != "{not json" {
+		t.Errorf("Corrupt config should not be overwritten, got: %s", string(data))
+	}
+}
+
+// TestLnbNameValidation tests that unsafe alias and binary names are rejected
+func TestLnbNameValidation(t *testing.T) {
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	validBinary := filepath.Join(testAssetsDir, "validbinary")
+	if err := os.WriteFile(validBinary, []byte(testBinaryContent), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		contains string
+	}{
+		{
+			name:     "alias_path_traversal",
+			args:     []string{"alias", "../../tmp/lnb-evil", "echo hi"},
+			contains: "name must not contain path separators",
+		},
+		{
+			name:     "alias_with_space",
+			args:     []string{"alias", "my alias", "echo hi"},
+			contains: "name must not contain whitespace",
+		},
+		{
+			name:     "alias_dot_dot",
+			args:     []string{"alias", "..", "echo hi"},
+			contains: "refer to directories",
+		},
+		{
+			name:     "alias_too_long",
+			args:     []string{"alias", strings.Repeat("a", 65), "echo hi"},
+			contains: "the maximum is 64",
+		},
+		{
+			name:     "install_as_traversal",
+			args:     []string{"install", validBinary, "--as", "../validbinary"},
+			contains: "name must not contain path separators",
+		},
+		{
+			name:     "alias_unknown_shim",
+			args:     []string{"alias", "shimtest", "echo hi", "--shim", "vbs"},
+			contains: "unknown shim style 'vbs'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := exec.Command(testLnbPath, tc.args...).CombinedOutput()
+			if err == nil {
+				t.Errorf("Expected error but got none. Output: %s", string(output))
+			}
+			if !strings.Contains(string(output), tc.contains) {
+				t.Errorf("Expected output to contain '%s', got: %s", tc.contains, string(output))
+			}
+		})
+	}
+
+	if _, err := os.Stat("/tmp/lnb-evil"); err == nil {
+		os.Remove("/tmp/lnb-evil")
+		t.Errorf("Alias name escaped the bin directory")
+	}
+}
+
+// TestLnbShadowing tests that names already on PATH need --allow-shadow and show up in which
+func TestLnbShadowing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping PATH shadowing test on Windows for now")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	// Put an existing command after the bin directory on PATH
+	otherDir := filepath.Join(testAssetsDir, "otherbin")
+	if err := os.MkdirAll(otherDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	existing := filepath.Join(otherDir, "lnbshadowtest")
+	if err := os.WriteFile(existing, []byte("#!/bin/sh\necho existing\n"), 0755); err != nil {
+		t.Fatalf("Failed to create existing command: %v", err)
+	}
+
+	env := append(os.Environ(), "PATH=/usr/local/bin"+string(os.PathListSeparator)+otherDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(testLnbPath, args...)
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+	defer run("unalias", "lnbshadowtest")
+
+	output, err := run("alias", "lnbshadowtest", "echo hi")
+	if err == nil {
+		t.Fatalf("Expected shadowing alias to be refused. Output: %s", output)
+	}
+	if !strings.Contains(output, "would shadow "+existing) || !strings.Contains(output, "--allow-shadow") {
+		t.Errorf("Expected shadowed command and hint in output, got: %s", output)
+	}
+
+	output, err = run("alias", "--allow-shadow", "lnbshadowtest", "echo hi")
+	if err != nil {
+		t.Fatalf("Expected --allow-shadow to create the alias: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "Warning: 'lnbshadowtest' would shadow "+existing) {
+		t.Errorf("Expected shadow warning, got: %s", output)
+	}
+
+	output, err = run("which", "lnbshadowtest")
+	if err != nil {
+		t.Fatalf("Failed to run which: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "1. /usr/local/bin/lnbshadowtest (lnb)  [runs]") ||
+		!strings.Contains(output, "2. "+existing+"  [shadowed]") {
+		t.Errorf("Expected full resolution chain, got: %s", output)
+	}
+}
+
+// TestLnbWSLInterop installs a fake Windows program the way it would be
+// installed inside WSL and checks its wrapper converts path arguments
+func TestLnbWSLInterop(t *testing.T) {
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	windowsTool := filepath.Join(testAssetsDir, "wsltool.exe")
+	fakeProgram := "#!/bin/sh\nfor a in \"$@\"; do printf 'arg: %s\\n' \"$a\"; done\n"
+	if err := os.WriteFile(windowsTool, []byte(fakeProgram), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+
+	cmd := exec.Command(testLnbPath, "install", windowsTool)
+	cmd.Env = append(os.Environ(), "WSL_DISTRO_NAME=Ubuntu")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to install: %v\nOutput: %s", err, string(output))
+	}
+	defer exec.Command(testLnbPath, "remove", "wsltool").Run()
+
+	if !strings.Contains(string(output), "Successfully installed 'wsltool'") {
+		t.Errorf("Expected the .exe extension to be dropped, got: %s", string(output))
+	}
+
+	target := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Installed: "); ok {
+			target, _, _ = strings.Cut(rest, " -> ")
+		}
+	}
+	if target == "" {
+		t.Fatalf("No target in output: %s", string(output))
+	}
+
+	output, err = exec.Command(target, "/mnt/c/Users/me/file.txt", "/?").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to run wrapper: %v\nOutput: %s", err, string(output))
+	}
+	if !strings.Contains(string(output), `arg: C:\Users\me\file.txt`) || !strings.Contains(string(output), "arg: /?") {
+		t.Errorf("Expected converted arguments, got: %s", string(output))
+	}
+
+	output, err = exec.Command(testLnbPath, "list").CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "shim") {
+		t.Errorf("Expected the entry to be listed as a shim, got: %s", string(output))
+	}
+}
+
+func TestLnbDesktopEntry(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("desktop entries are Linux only")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	dataHome := t.TempDir()
+	env := append(os.Environ(), "XDG_DATA_HOME="+dataHome)
+
+	guiBinary := filepath.Join(testAssetsDir, "guibinary")
+	if err := os.WriteFile(guiBinary, []byte(testBinaryContent), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+
+	cmd := exec.Command(testLnbPath, "install", guiBinary, "--desktop")
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to install: %v\nOutput: %s", err, string(output))
+	}
+	defer exec.Command(testLnbPath, "remove", "guibinary").Run()
+
+	desktopFile := filepath.Join(dataHome, "applications", "lnb-guibinary.desktop")
+	contents, err := os.ReadFile(desktopFile)
+	if err != nil {
+		t.Fatalf("Desktop entry was not written: %v\nOutput: %s", err, string(output))
+	}
+	if !strings.Contains(string(contents), "Name=Guibinary\n") {
+		t.Errorf("Unexpected desktop entry:\n%s", string(contents))
+	}
+
+	cmd = exec.Command(testLnbPath, "remove", "guibinary")
+	cmd.Env = env
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to remove: %v\nOutput: %s", err, string(output))
+	}
+	if _, err := os.Stat(desktopFile); !os.IsNotExist(err) {
+		t.Errorf("Desktop entry was not removed")
+	}
+}
+
+// TestLnbContainerAlias runs container aliases against a fake runtime that
+// prints the arguments it was called with
+func TestLnbContainerAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake runtime is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	fakeBin := t.TempDir()
+	fakeRuntime := "#!/bin/sh\nfor a in \"$@\"; do printf '<%s>\\n' \"$a\"; done\n"
+	for _, name := range []string{"docker", "podman"} {
+		if err := os.WriteFile(filepath.Join(fakeBin, name), []byte(fakeRuntime), 0755); err != nil {
+			t.Fatalf("Failed to create fake runtime: %v", err)
+		}
+	}
+	env := append(os.Environ(), "PATH="+fakeBin+string(os.PathListSeparator)+os.Getenv("PATH"), "NPM_TOKEN=secret")
+	workDir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		args     []string
+		contains []string
+	}{
+		{
+			name: "lnbctrdocker",
+			args: []string{"--runtime", "docker", "--env", "NPM_TOKEN,CI", "--run-arg=-p", "--run-arg=3000:3000", "node:20", "--", "npm", "run"},
+			contains: []string{
+				"<run>\n<--rm>\n<-i>\n<--user>\n<" + fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()) + ">\n",
+				"<-v>\n<" + workDir + ":/work>\n<-w>\n</work>\n",
+				"<-e>\n<NPM_TOKEN>\n<-e>\n<CI>\n<-p>\n<3000:3000>\n<node:20>\n<npm>\n<run>\n<hello world>\n",
+			},
+		},
+		{
+			name:     "lnbctrpodman",
+			args:     []string{"--runtime=podman", "--workdir", "/src", "alpine"},
+			contains: []string{"<run>\n<--rm>\n<-i>\n<--userns=keep-id>\n<-v>\n<" + workDir + ":/src>\n", "<alpine>\n<hello world>\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(testLnbPath, append([]string{"container-alias", tc.name}, tc.args...)...)
+			cmd.Env = env
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Failed to create container alias: %v\nOutput: %s", err, string(output))
+			}
+			defer exec.Command(testLnbPath, "unalias", tc.name).Run()
+
+			// Not a terminal, so no -t
+			run := exec.Command(filepath.Join("/usr/local/bin", tc.name), "hello world")
+			run.Env = env
+			run.Dir = workDir
+			output, err = run.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Failed to run container alias: %v\nOutput: %s", err, string(output))
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("Expected runtime arguments to contain %q, got:\n%s", want, string(output))
+				}
+			}
+			if strings.Contains(string(output), "<-t>") {
+				t.Errorf("Expected no -t without a terminal, got:\n%s", string(output))
+			}
+		})
+	}
+
+	output, _ := exec.Command(testLnbPath, "container-alias", "lnbctrbad", "alpine", "--runtime", "lxc").CombinedOutput()
+	if !strings.Contains(string(output), "unknown container runtime 'lxc'") {
+		t.Errorf("Expected an unknown runtime to be rejected, got: %s", string(output))
+	}
+}
+
+func TestLnbAliasEvaluation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the aliases use sh syntax")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	createDir := t.TempDir()
+	runDir := t.TempDir()
+	for _, name := range []string{"foo.bar", "data.txt"} {
+		if err := os.WriteFile(filepath.Join(createDir, name), []byte("from "+name+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			// Words with dots and $(...) are left for the wrapper, even if a
+			// file with that name exists where the alias was created
+			name:     "lnbevaldeferred",
+			args:     []string{"echo foo.bar $(pwd)"},
+			expected: "foo.bar " + runDir + "\n",
+		},
+		{
+			name:     "lnbevalrelative",
+			args:     []string{"cat ./data.txt"},
+			expected: "from data.txt\n",
+		},
+		{
+			name:     "lnbevalliteral",
+			args:     []string{"--literal", "cat ./data.txt 2>/dev/null || echo not here"},
+			expected: "not here\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(testLnbPath, append([]string{"alias", tc.name}, tc.args...)...)
+			cmd.Dir = createDir
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Failed to create alias: %v\nOutput: %s", err, string(output))
+			}
+			defer exec.Command(testLnbPath, "unalias", tc.name).Run()
+
+			run := exec.Command(filepath.Join("/usr/local/bin", tc.name))
+			run.Dir = runDir
+			output, err = run.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Failed to run alias: %v\nOutput: %s", err, string(output))
+			}
+			if string(output) != tc.expected {
+				t.Errorf("Expected output %q, got %q", tc.expected, string(output))
+			}
+		})
+	}
+}
+
+func TestLnbEditAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake editor is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	aliasName := "lnbedittest"
+	aliasPath := filepath.Join("/usr/local/bin", aliasName)
+	output, err := exec.Command(testLnbPath, "alias", aliasName, "echo one").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, string(output))
+	}
+	defer exec.Command(testLnbPath, "unalias", aliasName).Run()
+
+	installedLine := func() string {
+		output, _ := exec.Command(testLnbPath, "list").CombinedOutput()
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.Contains(line, "Installed:") {
+				return line
+			}
+		}
+		return ""
+	}
+	installed := installedLine()
+
+	expectOutput := func(want string) {
+		t.Helper()
+		output, err := exec.Command(aliasPath).CombinedOutput()
+		if err != nil || string(output) != want {
+			t.Errorf("Expected alias to print %q, got %q (err %v)", want, string(output), err)
+		}
+	}
+
+	output, err = exec.Command(testLnbPath, "alias", "--update", aliasName, "echo two").CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "Successfully updated alias") {
+		t.Fatalf("Failed to update alias: %v\nOutput: %s", err, string(output))
+	}
+	expectOutput("two\n")
+
+	editor := filepath.Join(t.TempDir(), "editor")
+	writeEditor := func(script string) {
+		t.Helper()
+		if err := os.WriteFile(editor, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatalf("Failed to create fake editor: %v", err)
+		}
+	}
+
+	writeEditor(`sed 's/"two"/"three"/' "$1" > "$1.new" && mv "$1.new" "$1"`)
+	edit := exec.Command(testLnbPath, "edit", aliasName)
+	edit.Env = append(os.Environ(), "VISUAL=", "EDITOR="+editor)
+	output, err = edit.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to edit alias: %v\nOutput: %s", err, string(output))
+	}
+	expectOutput("three\n")
+
+	// The alias can be named by its wrapper's path
+	writeEditor("true")
+	edit = exec.Command(testLnbPath, "edit", aliasPath)
+	edit.Env = append(os.Environ(), "VISUAL=", "EDITOR="+editor)
+	output, err = edit.CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "No changes") {
+		t.Errorf("Expected editing by path to open the alias, got: %v\nOutput: %s", err, string(output))
+	}
+
+	// An invalid edit is rejected and the alias keeps working
+	writeEditor(`echo '{"command": {"argv": []}}' > "$1"`)
+	edit = exec.Command(testLnbPath, "edit", aliasName)
+	edit.Env = append(os.Environ(), "VISUAL=", "EDITOR="+editor)
+	output, err = edit.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "command cannot be empty") {
+		t.Errorf("Expected an empty command to be rejected, got: %v\nOutput: %s", err, string(output))
+	}
+	expectOutput("three\n")
+
+	// The wrapper is rewritten where it is, even with another bin dir in use,
+	// and 'edit' is a command even next to a file called edit
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "edit"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to create a file called edit: %v", err)
+	}
+	otherBin := t.TempDir()
+	writeEditor(`sed 's/"three"/"four"/' "$1" > "$1.new" && mv "$1.new" "$1"`)
+	edit = exec.Command(testLnbPath, "edit", aliasName)
+	edit.Dir = workDir
+	edit.Env = append(os.Environ(), "VISUAL=", "EDITOR="+editor, "LNB_BIN_DIR="+otherBin)
+	output, err = edit.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to edit alias: %v\nOutput: %s", err, string(output))
+	}
+	expectOutput("four\n")
+	if entries, _ := os.ReadDir(otherBin); len(entries) != 0 {
+		t.Errorf("Expected nothing in the bin dir in use, found %d files", len(entries))
+	}
+
+	output, _ = exec.Command(testLnbPath, "list").CombinedOutput()
+	if !strings.Contains(string(output), "Updated:") {
+		t.Errorf("Expected the entry to show when it was updated, got: %s", string(output))
+	}
+	if got := installedLine(); got != installed {
+		t.Errorf("Expected the install time to be kept, got %q instead of %q", got, installed)
+	}
+
+	output, _ = exec.Command(testLnbPath, "alias", "--update", "lnbeditmissing", "echo x").CombinedOutput()
+	if !strings.Contains(string(output), "was not installed by LNB") {
+		t.Errorf("Expected updating a missing alias to fail, got: %s", string(output))
+	}
+}
+
+func TestLnbMove(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	binary := filepath.Join(testAssetsDir, "lnbmvtool")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho tool\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+	for _, name := range []string{"lnbmvtool", "lnbmvrenamed", "lnbmvalias", "lnbmvaliasnew"} {
+		defer run("remove", name)
+	}
+
+	if output, err := run(binary); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	if output, err := run("alias", "lnbmvalias", "echo alias"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+
+	testCases := []struct {
+		oldName  string
+		newName  string
+		expected string
+	}{
+		{"lnbmvtool", "lnbmvrenamed", "tool\n"},
+		{"lnbmvalias", "lnbmvaliasnew", "alias\n"},
+	}
+
+	for _, tc := range testCases {
+		output, err := run("mv", tc.oldName, tc.newName)
+		if err != nil {
+			t.Fatalf("Failed to move %s: %v\nOutput: %s", tc.oldName, err, output)
+		}
+		if _, err := os.Lstat(filepath.Join("/usr/local/bin", tc.oldName)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be gone after the move", tc.oldName)
+		}
+		output2, err := exec.Command(filepath.Join("/usr/local/bin", tc.newName)).CombinedOutput()
+		if err != nil || string(output2) != tc.expected {
+			t.Errorf("Expected %s to print %q, got %q (err %v)", tc.newName, tc.expected, string(output2), err)
+		}
+	}
+
+	output, _ := run("list")
+	if strings.Contains(output, "  lnbmvtool\n") || !strings.Contains(output, "  lnbmvrenamed\n") || !strings.Contains(output, "Source:    "+binary) {
+		t.Errorf("Expected the renamed binary to keep its source, got: %s", output)
+	}
+
+	// The new name gets the same checks as a fresh install
+	output, err := run("mv", "lnbmvrenamed", "lnbmvaliasnew")
+	if err == nil || !strings.Contains(output, "already installed") {
+		t.Errorf("Expected a move onto an existing entry to fail, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("mv", "lnbmvrenamed", "../evil")
+	if err == nil || !strings.Contains(output, "invalid name") {
+		t.Errorf("Expected an invalid name to be rejected, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("mv", "lnbmvrenamed", "ls")
+	if err == nil || !strings.Contains(output, "--allow-shadow") {
+		t.Errorf("Expected a shadowing name to be refused, got: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Lstat("/usr/local/bin/lnbmvrenamed"); err != nil {
+		t.Errorf("Expected a refused move to leave the entry in place: %v", err)
+	}
+
+	// An entry keeps the bin dir it was created in, even once another is in use
+	otherBin := t.TempDir()
+	move := exec.Command(testLnbPath, "mv", "lnbmvrenamed", "lnbmvtool")
+	move.Env = append(os.Environ(), "LNB_BIN_DIR="+otherBin)
+	if output, err := move.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to move with another bin dir: %v\nOutput: %s", err, output)
+	}
+	if target, err := os.Readlink("/usr/local/bin/lnbmvtool"); err != nil || target != binary {
+		t.Errorf("Expected the moved link to stay in /usr/local/bin and point at %s, got %q (err %v)", binary, target, err)
+	}
+	if entries, _ := os.ReadDir(otherBin); len(entries) != 0 {
+		t.Errorf("Expected nothing in the bin dir in use, found %d files", len(entries))
+	}
+}
+
+func TestLnbInfoAndCat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the tampering check edits a sh script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	binary := filepath.Join(testAssetsDir, "lnbinfotool")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho tool\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+	if output, err := run(binary); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	defer run("remove", "lnbinfotool")
+	if output, err := run("alias", "lnbinfoalias", "echo info"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbinfoalias")
+
+	output, err := run("info", "lnbinfoalias")
+	if err != nil {
+		t.Fatalf("Failed to run info: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{"Command:   echo info", "/usr/local/bin/lnbinfoalias (sh script)", "Healthy"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected info to contain %q, got: %s", want, output)
+		}
+	}
+
+	output, _ = run("info", "lnbinfotool")
+	if !strings.Contains(output, "(symlink to "+binary+")") {
+		t.Errorf("Expected info to show the symlink, got: %s", output)
+	}
+	output, _ = run("cat", "lnbinfotool")
+	if !strings.Contains(output, "/usr/local/bin/lnbinfotool -> "+binary) {
+		t.Errorf("Expected cat to show the symlink, got: %s", output)
+	}
+
+	output, err = run("cat", "lnbinfoalias")
+	if err != nil || !strings.HasPrefix(output, "#!") || !strings.Contains(output, "echo info") {
+		t.Errorf("Expected cat to print the wrapper, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("cat", "--diff", "lnbinfoalias")
+	if err != nil || !strings.Contains(output, "matches what lnb generates today") {
+		t.Errorf("Expected an untouched wrapper to match, got: %v\nOutput: %s", err, output)
+	}
+
+	f, err := os.OpenFile("/usr/local/bin/lnbinfoalias", os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open wrapper: %v", err)
+	}
+	f.WriteString("echo tampered\n")
+	f.Close()
+
+	output, err = run("cat", "--diff", "lnbinfoalias")
+	if err == nil || !strings.Contains(output, "-echo tampered") {
+		t.Errorf("Expected the change to be shown and reported, got: %v\nOutput: %s", err, output)
+	}
+	output, _ = run("info", "lnbinfoalias")
+	if strings.Contains(output, "Healthy") || !strings.Contains(output, "differs from what lnb generates today") {
+		t.Errorf("Expected info to report the changed wrapper, got: %s", output)
+	}
+}
+
+func TestLnbListFilters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	binary := filepath.Join(testAssetsDir, "lnblsbin")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho bin\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+	if output, err := run(binary); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	defer run("remove", "lnblsbin")
+
+	// Created in this order, so installed sorts them the other way round
+	aliases := [][]string{{"lnblsb-ps", "docker ps"}, {"lnblsa-logs", "docker logs -f"}, {"lnblsc", "git status"}}
+	for _, alias := range aliases {
+		if output, err := run("alias", alias[0], alias[1]); err != nil {
+			t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+		}
+		defer run("unalias", alias[0])
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// names returns the entry names of a --compact listing in order
+	names := func(args ...string) []string {
+		t.Helper()
+		output, err := run(args...)
+		if err != nil {
+			t.Fatalf("lnb %v failed: %v\nOutput: %s", args, err, output)
+		}
+		var found []string
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n")[1:] {
+			found = append(found, strings.Fields(line)[0])
+		}
+		return found
+	}
+
+	// Access times: lnblsc was run most recently, lnblsa-logs longest ago
+	for i, name := range []string{"lnblsa-logs", "lnblsb-ps", "lnblsc"} {
+		at := time.Now().Add(time.Duration(i-3) * time.Hour)
+		if err := os.Chtimes(filepath.Join("/usr/local/bin", name), at, at); err != nil {
+			t.Fatalf("Failed to set access time: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--compact"}, "lnblsa-logs lnblsb-ps lnblsbin lnblsc"},
+		{[]string{"list", "--compact", "--type", "alias"}, "lnblsa-logs lnblsb-ps lnblsc"},
+		{[]string{"list", "--compact", "--type", "binary"}, "lnblsbin"},
+		{[]string{"list", "--compact", "lnbls?-*"}, "lnblsa-logs lnblsb-ps"},
+		{[]string{"list", "--compact", "--regex", "^lnbls[bc]$"}, "lnblsc"},
+		{[]string{"list", "--compact", "--type", "alias", "--sort", "installed"}, "lnblsc lnblsa-logs lnblsb-ps"},
+		{[]string{"list", "--compact", "--type", "alias", "--sort", "used"}, "lnblsc lnblsb-ps lnblsa-logs"},
+		{[]string{"search", "--compact", "DOCKER"}, "lnblsa-logs lnblsb-ps"},
+		{[]string{"search", "--compact", "logs -f"}, "lnblsa-logs"},
+	}
+	for _, tc := range testCases {
+		if got := strings.Join(names(tc.args...), " "); got != tc.want {
+			t.Errorf("lnb %s = %q, want %q", strings.Join(tc.args, " "), got, tc.want)
+		}
+	}
+
+	output, err := run("list", "--sort", "size")
+	if err == nil || !strings.Contains(output, "unknown sort order 'size'") {
+		t.Errorf("Expected an unknown sort order to be rejected, got: %v\nOutput: %s", err, output)
+	}
+	output, _ = run("search", "nothing-has-this")
+	if !strings.Contains(output, "No entries match.") {
+		t.Errorf("Expected an empty search to say so, got: %s", output)
+	}
+}
+
+func TestLnbTags(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	binary := filepath.Join(testAssetsDir, "lnbtagbin")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho bin\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+	if output, err := run(binary, "--tag", "Old-Project", "--desc", "A throwaway binary"); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	defer run("remove", "lnbtagbin")
+	if output, err := run("alias", "lnbtag-a", "echo a", "--tag", "infra,old-project"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbtag-a")
+	if output, err := run("alias", "lnbtag-b", "echo b", "--tag", "infra"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbtag-b")
+
+	output, err := run("info", "lnbtagbin")
+	if err != nil || !strings.Contains(output, "About:     A throwaway binary") || !strings.Contains(output, "Tags:      old-project") {
+		t.Errorf("Expected info to show the description and tags, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("tag", "lnbtag-b", "Work", "--remove", "infra", "--desc", "Prints b")
+	if err != nil || !strings.Contains(output, "Tags:      work") || !strings.Contains(output, "About:     Prints b") {
+		t.Errorf("Expected tag to change the tags and description, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("list", "--compact", "--tag", "infra")
+	if err != nil || !strings.Contains(output, "lnbtag-a") || strings.Contains(output, "lnbtag-b") {
+		t.Errorf("Expected list --tag infra to show only lnbtag-a, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("search", "--compact", "prints b")
+	if err != nil || !strings.Contains(output, "lnbtag-b") {
+		t.Errorf("Expected search to match the description, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("tag", "lnbtag-b", "no spaces")
+	if err == nil || !strings.Contains(output, "invalid tag") {
+		t.Errorf("Expected an invalid tag to be rejected, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("remove", "--tag", "old-project")
+	if err != nil || !strings.Contains(output, "removed 2 entries") {
+		t.Fatalf("Failed to remove tagged entries: %v\nOutput: %s", err, output)
+	}
+	for _, name := range []string{"lnbtagbin", "lnbtag-a"} {
+		if _, err := os.Lstat(filepath.Join("/usr/local/bin", name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+	if _, err := os.Lstat("/usr/local/bin/lnbtag-b"); err != nil {
+		t.Errorf("Expected lnbtag-b to be kept: %v", err)
+	}
+
+	output, err = run("remove", "--tag", "old-project")
+	if err == nil || !strings.Contains(output, "no entries are tagged 'old-project'") {
+		t.Errorf("Expected removing an unused tag to fail, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func TestLnbProfiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+	defer cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	clientBin := filepath.Join(testAssetsDir, "clienta-bin")
+	defaultDeploy := "/usr/local/bin/lnbprof-deploy"
+	clientDeploy := filepath.Join(clientBin, "lnbprof-deploy")
+
+	if output, err := run("alias", "lnbprof-deploy", "echo default"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer os.Remove(defaultDeploy)
+	if output, err := run("profile", "create", "clienta", "--bin-dir", clientBin); err != nil {
+		t.Fatalf("Failed to create profile: %v\nOutput: %s", err, output)
+	}
+
+	output, err := run("profile", "use", "clienta")
+	if err != nil || !strings.Contains(output, "Switched from profile 'default' to 'clienta'") {
+		t.Fatalf("Failed to switch profiles: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Lstat(defaultDeploy); !os.IsNotExist(err) {
+		t.Errorf("Expected the default profile's alias to be removed")
+	}
+
+	// The same name can mean something else in another profile
+	if output, err := run("alias", "lnbprof-deploy", "echo clienta"); err != nil {
+		t.Fatalf("Failed to create alias in clienta: %v\nOutput: %s", err, output)
+	}
+	if out, err := exec.Command(clientDeploy).Output(); err != nil || strings.TrimSpace(string(out)) != "clienta" {
+		t.Errorf("Expected clienta's alias in its own bin dir, got %q: %v", out, err)
+	}
+	if output, err := run("alias", "lnbprof-tail", "echo tail"); err != nil {
+		t.Fatalf("Failed to create alias in clienta: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("list", "--compact", "--all-profiles")
+	if err != nil || !strings.Contains(output, "echo default") || !strings.Contains(output, "echo clienta") {
+		t.Errorf("Expected --all-profiles to list both profiles, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("profile")
+	if err != nil || !strings.Contains(output, "* clienta") {
+		t.Errorf("Expected clienta to be marked as in use, got: %v\nOutput: %s", err, output)
+	}
+
+	if output, err := run("profile", "use", "default"); err != nil {
+		t.Fatalf("Failed to switch back: %v\nOutput: %s", err, output)
+	}
+	if out, err := exec.Command(defaultDeploy).Output(); err != nil || strings.TrimSpace(string(out)) != "default" {
+		t.Errorf("Expected the default alias to be back, got %q: %v", out, err)
+	}
+	if _, err := os.Lstat(clientDeploy); !os.IsNotExist(err) {
+		t.Errorf("Expected clienta's alias to be removed")
+	}
+	output, err = run("list", "--compact", "--profile", "clienta")
+	if err != nil || !strings.Contains(output, "echo clienta") {
+		t.Errorf("Expected list --profile to show clienta's entries, got: %v\nOutput: %s", err, output)
+	}
+
+	// An entry that cannot be created keeps its record and holds nothing back
+	blocker := filepath.Join(clientBin, "lnbprof-tail")
+	if err := os.WriteFile(blocker, []byte("not lnb's\n"), 0644); err != nil {
+		t.Fatalf("Failed to create a file in the way: %v", err)
+	}
+	history, _ := run("history")
+	output, err = run("profile", "use", "clienta")
+	if err != nil || !strings.Contains(output, "'lnbprof-tail' could not be created") {
+		t.Errorf("Expected the switch to warn about lnbprof-tail, got: %v\nOutput: %s", err, output)
+	}
+	if out, err := exec.Command(clientDeploy).Output(); err != nil || strings.TrimSpace(string(out)) != "clienta" {
+		t.Errorf("Expected clienta's other alias to be created, got %q: %v", out, err)
+	}
+	if data, _ := os.ReadFile(blocker); string(data) != "not lnb's\n" {
+		t.Errorf("Expected the file in the way to be left alone, got %q", data)
+	}
+	if after, _ := run("history"); after != history {
+		t.Errorf("Expected the switch to leave the history alone, got:\n%s\nbefore:\n%s", after, history)
+	}
+	output, _ = run("list", "--compact")
+	if !strings.Contains(output, "echo tail") {
+		t.Errorf("Expected lnbprof-tail to keep its record, got: %s", output)
+	}
+	os.Remove(blocker)
+	if output, err := run("profile", "use", "default"); err != nil {
+		t.Fatalf("Failed to switch back: %v\nOutput: %s", err, output)
+	}
+
+	// A binary whose source is gone does not keep a profile from coming back
+	source := filepath.Join(testAssetsDir, "lnbprof-bin")
+	if err := os.WriteFile(source, []byte("#!/bin/sh\necho bin\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+	if output, err := run(source); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	if output, err := run("profile", "use", "clienta"); err != nil {
+		t.Fatalf("Failed to switch profiles: %v\nOutput: %s", err, output)
+	}
+	os.Remove(source)
+	output, err = run("profile", "use", "default")
+	if err != nil || !strings.Contains(output, "'lnbprof-bin' could not be created") {
+		t.Errorf("Expected the switch back to warn about the missing source, got: %v\nOutput: %s", err, output)
+	}
+	if out, err := exec.Command(defaultDeploy).Output(); err != nil || strings.TrimSpace(string(out)) != "default" {
+		t.Errorf("Expected the default alias to be back, got %q: %v", out, err)
+	}
+	if output, err := run("remove", "lnbprof-bin"); err != nil {
+		t.Errorf("Expected the stale entry to be removable: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("profile", "use", "nope")
+	if err == nil || !strings.Contains(output, "there is no profile called 'nope'") {
+		t.Errorf("Expected an unknown profile to be rejected, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("profile", "delete", "default")
+	if err == nil {
+		t.Errorf("Expected the profile in use not to be deleted\nOutput: %s", output)
+	}
+	if output, err := run("profile", "delete", "clienta"); err != nil {
+		t.Errorf("Failed to delete profile: %v\nOutput: %s", err, output)
+	}
+	run("unalias", "lnbprof-deploy")
+}
+
+func TestLnbLocalAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the wrappers are shell scripts")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+	defer cleanupConfig()
+
+	projectDir := filepath.Join(testAssetsDir, "lnblocal-project")
+	subDir := filepath.Join(projectDir, "backend", "pkg")
+	if err := os.MkdirAll(filepath.Join(projectDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// runIn runs a command from dir
+	runIn := func(dir, program string, args ...string) (string, error) {
+		cmd := exec.Command(program, args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+	wrapper := "/usr/local/bin/lnblocal-build"
+
+	if output, err := runIn(testAssetsDir, testLnbPath, "alias", "lnblocal-build", "echo global"); err != nil {
+		t.Fatalf("Failed to create global alias: %v\nOutput: %s", err, output)
+	}
+	defer runIn(testAssetsDir, testLnbPath, "unalias", "lnblocal-build")
+
+	output, err := runIn(subDir, testLnbPath, "alias", "--local", "lnblocal-build", "echo project in $(pwd)")
+	if err != nil {
+		t.Fatalf("Failed to create local alias: %v\nOutput: %s", err, output)
+	}
+	projectFile := filepath.Join(projectDir, ".lnb.yaml")
+	content, err := os.ReadFile(projectFile)
+	if err != nil || !strings.Contains(string(content), "lnblocal-build: echo project in $(pwd)") {
+		t.Fatalf("Expected the alias in %s, got %q: %v", projectFile, content, err)
+	}
+
+	// Inside the project the project definition runs from the project root
+	output, err = runIn(subDir, wrapper, "arg")
+	if err != nil || strings.TrimSpace(output) != "project in "+projectDir+" arg" {
+		t.Errorf("Expected the project definition, got %q: %v", output, err)
+	}
+	// Outside it the global alias does
+	output, err = runIn(testAssetsDir, wrapper, "arg")
+	if err != nil || strings.TrimSpace(output) != "global arg" {
+		t.Errorf("Expected the global alias, got %q: %v", output, err)
+	}
+
+	// A changed project file has to be trusted again
+	if err := os.WriteFile(projectFile, append(content, "  other: echo other\n"...), 0644); err != nil {
+		t.Fatalf("Failed to change project file: %v", err)
+	}
+	output, err = runIn(subDir, wrapper)
+	if err == nil || !strings.Contains(output, "lnb trust") {
+		t.Errorf("Expected a changed project file to be refused, got: %v\nOutput: %s", err, output)
+	}
+	if output, err := runIn(subDir, testLnbPath, "trust"); err != nil {
+		t.Fatalf("Failed to trust project file: %v\nOutput: %s", err, output)
+	}
+	if output, err := runIn(subDir, wrapper); err != nil || !strings.Contains(output, "project in") {
+		t.Errorf("Expected the trusted project definition to run, got %q: %v", output, err)
+	}
+
+	if output, err := runIn(subDir, testLnbPath, "unalias", "--local", "lnblocal-build"); err != nil {
+		t.Fatalf("Failed to remove local alias: %v\nOutput: %s", err, output)
+	}
+	output, err = runIn(subDir, wrapper)
+	if err != nil || strings.TrimSpace(output) != "global" {
+		t.Errorf("Expected the global alias after removing the local one, got %q: %v", output, err)
+	}
+}
+
+func TestLnbAliasVariants(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the aliases use sh syntax")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	output, err := run("alias", "lnbvariant", "echo default",
+		"--linux", "echo linux", "--darwin", "echo darwin", "--windows", "echo windows")
+	if err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbvariant")
+
+	// Only the variant for this OS is installed
+	out, err := exec.Command("/usr/local/bin/lnbvariant").Output()
+	if err != nil || strings.TrimSpace(string(out)) != runtime.GOOS {
+		t.Errorf("Expected the %s variant to run, got %q: %v", runtime.GOOS, out, err)
+	}
+
+	output, err = run("list", "lnbvariant")
+	for _, want := range []string{"Command:   echo default", "darwin:    echo darwin", "linux:     echo linux", "windows:   echo windows"} {
+		if err != nil || !strings.Contains(output, want) {
+			t.Errorf("Expected list to show %q, got: %v\nOutput: %s", want, err, output)
+		}
+	}
+
+	// Updating the default keeps the variants
+	if output, err := run("alias", "--update", "lnbvariant", "echo new default"); err != nil {
+		t.Fatalf("Failed to update alias: %v\nOutput: %s", err, output)
+	}
+	out, err = exec.Command("/usr/local/bin/lnbvariant").Output()
+	if err != nil || strings.TrimSpace(string(out)) != runtime.GOOS {
+		t.Errorf("Expected the %s variant to survive an update, got %q: %v", runtime.GOOS, out, err)
+	}
+
+	output, err = run("alias", "lnbvariant-bad", "echo x", "--linux", "")
+	if err == nil || !strings.Contains(output, "command cannot be empty") {
+		t.Errorf("Expected an empty variant to be rejected, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func TestLnbHistoryUndo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the aliases use sh syntax")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+	runAlias := func() string {
+		out, _ := exec.Command("/usr/local/bin/lnbundo").Output()
+		return strings.TrimSpace(string(out))
+	}
+
+	if output, err := run("alias", "lnbundo", "echo first"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbundo")
+	if output, err := run("alias", "--update", "lnbundo", "echo second"); err != nil {
+		t.Fatalf("Failed to update alias: %v\nOutput: %s", err, output)
+	}
+	if output, err := run("unalias", "lnbundo"); err != nil {
+		t.Fatalf("Failed to remove alias: %v\nOutput: %s", err, output)
+	}
+
+	output, err := run("history")
+	for _, want := range []string{"alias", "edit", "remove", "lnbundo"} {
+		if err != nil || !strings.Contains(output, want) {
+			t.Errorf("Expected history to show %q, got: %v\nOutput: %s", want, err, output)
+		}
+	}
+
+	// Undoing the removal brings the alias back as it was
+	output, err = run("undo")
+	if err != nil || !strings.Contains(output, "Undid #3") {
+		t.Fatalf("Failed to undo the removal: %v\nOutput: %s", err, output)
+	}
+	if got := runAlias(); got != "second" {
+		t.Errorf("Expected the restored alias to print second, got %q", got)
+	}
+
+	// The removal is done with, so the next undo reverses the edit
+	output, err = run("undo")
+	if err != nil || !strings.Contains(output, "Undid #2") {
+		t.Fatalf("Failed to undo the edit: %v\nOutput: %s", err, output)
+	}
+	if got := runAlias(); got != "first" {
+		t.Errorf("Expected undoing the edit to restore the old command, got %q", got)
+	}
+
+	output, err = run("undo", "3")
+	if err == nil || !strings.Contains(output, "already undone") {
+		t.Errorf("Expected undoing #3 twice to be refused, got: %v\nOutput: %s", err, output)
+	}
+
+	// Redoing the edit is undoing its undo
+	output, err = run("history")
+	if err != nil || !strings.Contains(output, "(undone by #5)") {
+		t.Fatalf("Expected history to mark the undone edit, got: %v\nOutput: %s", err, output)
+	}
+	if output, err := run("undo", "5"); err != nil {
+		t.Fatalf("Failed to redo the edit: %v\nOutput: %s", err, output)
+	}
+	if got := runAlias(); got != "second" {
+		t.Errorf("Expected the redone edit to print second, got %q", got)
+	}
+}
+
+func TestLnbExportImport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is a shell script")
+	}
+
+	// Set up test environment
+	_, testLnbPath, testAssetsDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+
+	binary := filepath.Join(testAssetsDir, "lnbexportbin")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho exported\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test binary: %v", err)
+	}
+	if output, err := run(binary, "--tag", "move"); err != nil {
+		t.Fatalf("Failed to install binary: %v\nOutput: %s", err, output)
+	}
+	defer run("remove", "lnbexportbin")
+	if output, err := run("alias", "lnbexport-a", "echo a", "--tag", "move", "--desc", "Says a"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbexport-a")
+	if output, err := run("alias", "lnbexport-b", "echo b"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbexport-b")
+
+	bundle := filepath.Join(testAssetsDir, "bundle.json")
+	output, err := run("export", "--tag", "move", "--embed", "-o", bundle)
+	if err != nil || !strings.Contains(output, "Exported 2 entries") {
+		t.Fatalf("Failed to export: %v\nOutput: %s", err, output)
+	}
+
+	// The binary's source is gone on the "new machine"; the bundle carries it
+	run("remove", "lnbexportbin")
+	run("unalias", "lnbexport-a")
+	os.Remove(binary)
+
+	output, err = run("import", bundle)
+	if err != nil || !strings.Contains(output, "Imported 2 entries (0 skipped, 0 failed)") {
+		t.Fatalf("Failed to import: %v\nOutput: %s", err, output)
+	}
+	if out, err := exec.Command("/usr/local/bin/lnbexportbin").Output(); err != nil || strings.TrimSpace(string(out)) != "exported" {
+		t.Errorf("Expected the embedded binary to run, got %q: %v", out, err)
+	}
+	output, err = run("info", "lnbexport-a")
+	if err != nil || !strings.Contains(output, "About:     Says a") || !strings.Contains(output, "Tags:      move") {
+		t.Errorf("Expected the alias to keep its description and tags, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("import", bundle)
+	if err != nil || !strings.Contains(output, "Imported 0 entries (2 skipped, 0 failed)") {
+		t.Errorf("Expected a second import to skip both entries, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = run("import", bundle, "--on-conflict", "rename")
+	if err != nil || !strings.Contains(output, "Imported 'lnbexport-a' as 'lnbexport-a-2'") {
+		t.Errorf("Expected the alias to be imported under a new name, got: %v\nOutput: %s", err, output)
+	}
+	defer run("remove", "lnbexportbin-2")
+	defer run("unalias", "lnbexport-a-2")
+
+	// Overwriting replaces the entry in place, and undo puts the replaced one back
+	if output, err := run("alias", "--update", "lnbexport-a", "echo changed"); err != nil {
+		t.Fatalf("Failed to update alias: %v\nOutput: %s", err, output)
+	}
+	output, err = run("import", bundle, "--on-conflict", "overwrite")
+	if err != nil || !strings.Contains(output, "Imported 2 entries (0 skipped, 0 failed)") {
+		t.Fatalf("Failed to import with overwrite: %v\nOutput: %s", err, output)
+	}
+	runA := func() string {
+		out, _ := exec.Command("/usr/local/bin/lnbexport-a").Output()
+		return strings.TrimSpace(string(out))
+	}
+	if got := runA(); got != "a" {
+		t.Errorf("Expected the overwritten alias to print a, got %q", got)
+	}
+	output, err = run("history")
+	if err != nil || strings.Contains(output, "lnbexport-a, lnbexport-a") {
+		t.Errorf("Expected the import to record each entry once, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("undo")
+	if err != nil || !strings.Contains(output, "Undid") {
+		t.Fatalf("Failed to undo the overwrite: %v\nOutput: %s", err, output)
+	}
+	if got := runA(); got != "changed" {
+		t.Errorf("Expected undo to restore the replaced alias, got %q", got)
+	}
+
+	output, err = run("import", bundle, "--on-conflict", "sideways")
+	if err == nil || !strings.Contains(output, "unknown conflict strategy") {
+		t.Errorf("Expected an unknown strategy to be rejected, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func TestLnbSync(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the aliases use sh syntax")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@localhost"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\nOutput: %s", args, err, output)
+		}
+	}
+
+	// A bare repository stands in for the shared remote
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	git("", "init", "--quiet", "--bare", remote)
+
+	if output, err := run("sync", "init", remote); err != nil {
+		t.Fatalf("Failed to set up sync: %v\nOutput: %s", err, output)
+	}
+	if output, err := run("alias", "lnbsync-a", "echo a", "--tag", "shared"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbsync-a")
+	output, err := run("sync", "push")
+	if err != nil || !strings.Contains(output, "Pushed to the sync repository") {
+		t.Fatalf("Failed to push: %v\nOutput: %s", err, output)
+	}
+
+	// Another machine changes one entry and adds another
+	other := filepath.Join(t.TempDir(), "other")
+	git("", "clone", "--quiet", remote, other)
+	data, err := os.ReadFile(filepath.Join(other, "entries", "lnbsync-a.json"))
+	if err != nil || !strings.Contains(string(data), `"shared"`) {
+		t.Fatalf("Expected the pushed entry with its tags, got %q: %v", data, err)
+	}
+	changed := strings.Replace(string(data), `"a"`, `"changed"`, 1)
+	if err := os.WriteFile(filepath.Join(other, "entries", "lnbsync-a.json"), []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := `{"name": "lnbsync-b", "kind": "alias", "command": {"argv": ["echo", "b"]}, "target_path": "", "installed_at": "2024-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(other, "entries", "lnbsync-b.json"), []byte(added), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(other, "add", "--all")
+	git(other, "commit", "--quiet", "-m", "change a, add b")
+	git(other, "push", "--quiet", "origin", "HEAD")
+
+	output, err = run("sync", "pull")
+	if err != nil || !strings.Contains(output, "Updated 'lnbsync-a'") || !strings.Contains(output, "Added 'lnbsync-b'") {
+		t.Fatalf("Failed to pull: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbsync-b")
+	for name, want := range map[string]string{"lnbsync-a": "changed", "lnbsync-b": "b"} {
+		out, err := exec.Command("/usr/local/bin/" + name).Output()
+		if err != nil || strings.TrimSpace(string(out)) != want {
+			t.Errorf("Expected %s to print %q after the pull, got %q: %v", name, want, out, err)
+		}
+	}
+
+	// Nothing changed since, so there is nothing to push
+	output, err = run("sync", "push")
+	if err != nil || !strings.Contains(output, "up to date") {
+		t.Errorf("Expected nothing to push, got: %v\nOutput: %s", err, output)
+	}
+
+	// Entries whose names could reach outside the repository, or that are not
+	// in a file of their own name, are refused
+	for file, name := range map[string]string{"evil.json": "../../lnbsync-evil", "lnbsync-c.json": "lnbsync-d"} {
+		git(other, "pull", "--quiet")
+		entry := `{"name": "` + name + `", "kind": "alias", "command": {"argv": ["echo", "x"]}, "target_path": "", "installed_at": "2024-01-01T00:00:00Z"}`
+		if err := os.WriteFile(filepath.Join(other, "entries", file), []byte(entry), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(other, "add", "--all")
+		git(other, "commit", "--quiet", "-m", "add "+file)
+		git(other, "push", "--quiet", "origin", "HEAD")
+
+		output, err = run("sync", "push")
+		if err == nil || !strings.Contains(output, "in the sync repository") {
+			t.Errorf("Expected %s to be refused, got: %v\nOutput: %s", file, err, output)
+		}
+		git(other, "rm", "--quiet", filepath.Join("entries", file))
+		git(other, "commit", "--quiet", "-m", "remove "+file)
+		git(other, "push", "--quiet", "origin", "HEAD")
+	}
+	if output, err := run("sync", "push"); err != nil {
+		t.Errorf("Expected the sync to work again, got: %v\nOutput: %s", err, output)
+	}
+
+	// An entry that cannot be created here is neither taken for a local
+	// removal nor deleted from the repository by the next push
+	git(other, "pull", "--quiet")
+	shadowing := `{"name": "ls", "kind": "alias", "command": {"argv": ["echo", "ls"]}, "target_path": "", "installed_at": "2024-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(other, "entries", "ls.json"), []byte(shadowing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(other, "add", "--all")
+	git(other, "commit", "--quiet", "-m", "add ls")
+	git(other, "push", "--quiet", "origin", "HEAD")
+
+	output, err = run("sync", "pull")
+	if err == nil || !strings.Contains(output, "'ls'") {
+		t.Errorf("Expected the pull of ls to fail, got: %v\nOutput: %s", err, output)
+	}
+	output, err = run("sync", "push")
+	if err == nil || strings.Contains(output, "Local change to 'ls'") {
+		t.Errorf("Expected the push to leave ls alone and report it, got: %v\nOutput: %s", err, output)
+	}
+	git(other, "pull", "--quiet")
+	if _, err := os.Stat(filepath.Join(other, "entries", "ls.json")); err != nil {
+		t.Errorf("The push deleted the entry it could not pull: %v", err)
+	}
+	git(other, "rm", "--quiet", filepath.Join("entries", "ls.json"))
+	git(other, "commit", "--quiet", "-m", "remove ls")
+	git(other, "push", "--quiet", "origin", "HEAD")
+	if output, err := run("sync", "push"); err != nil {
+		t.Errorf("Expected the sync to work again, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func TestLnbDryRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the aliases use sh syntax")
+	}
+
+	// Set up test environment
+	_, testLnbPath, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cleanupConfig()
+
+	run := func(args ...string) (string, error) {
+		output, err := exec.Command(testLnbPath, args...).CombinedOutput()
+		return string(output), err
+	}
+	target := "/usr/local/bin/lnbdry"
+
+	// Creating an alias lists its wrapper and the config update, and creates nothing
+	output, err := run("alias", "lnbdry", "echo planned", "--dry-run")
+	if err != nil {
+		t.Fatalf("Failed to plan the alias: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{"Planned changes:", "write", target, "echo planned", "update config"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the plan to mention %q\nOutput: %s", want, output)
+		}
+	}
+	if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		t.Fatalf("A dry run created %s: %v", target, err)
+	}
+	if output, err := run("list"); err != nil || strings.Contains(output, "lnbdry") {
+		t.Errorf("A dry run recorded the alias: %v\nOutput: %s", err, output)
+	}
+
+	// Removing it for real afterwards would only be planned, leaving it in place
+	if output, err := run("alias", "lnbdry", "echo real"); err != nil {
+		t.Fatalf("Failed to create alias: %v\nOutput: %s", err, output)
+	}
+	defer run("unalias", "lnbdry")
+	output, err = run("--dry-run", "unalias", "lnbdry")
+	if err != nil || !strings.Contains(output, "remove") || !strings.Contains(output, target) {
+		t.Errorf("Expected the plan to remove %s, got: %v\nOutput: %s", target, err, output)
+	}
+	if _, err := os.Lstat(target); err != nil {
+		t.Errorf("A dry run removed %s: %v", target, err)
+	}
+
+	// A name that is taken fails the same way it would for real
+	output, err = run("alias", "lnbdry", "echo again", "--dry-run")
+	if err == nil || !strings.Contains(output, "already installed") {
+		t.Errorf("Expected the planned alias to be refused, got: %v\nOutput: %s", err, output)
+	}
+
+	// Writing a bundle is planned like any other file
+	bundle := filepath.Join(t.TempDir(), "bundle.json")
+	output, err = run("export", "-o", bundle, "--dry-run")
+	if err != nil || !strings.Contains(output, bundle) || strings.Contains(output, "No files would change") {
+		t.Errorf("Expected the plan to write %s, got: %v\nOutput: %s", bundle, err, output)
+	}
+	if _, err := os.Stat(bundle); !os.IsNotExist(err) {
+		t.Errorf("A dry run wrote %s: %v", bundle, err)
+	}
+
+	output, err = run("sync", "pull", "--dry-run")
+	if err == nil || !strings.Contains(output, "does not support --dry-run") {
+		t.Errorf("Expected sync to refuse --dry-run, got: %v\nOutput: %s", err, output)
+	}
+}
@@ -0,0 +1,20 @@
+// Package atime reads the time a file was last accessed, which lnb uses as
+// the time a command was last run. File systems record this coarsely: Linux
+// mounts with relatime update it at most once a day, and noatime mounts never
+// do, so treat it as an approximation.
+package atime
+
+import (
+	"os"
+	"time"
+)
+
+// Of returns when the file at path was last accessed, following symlinks.
+// It reports false if the time is not available.
+func Of(path string) (time.Time, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return fromInfo(info)
+}
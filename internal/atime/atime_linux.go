@@ -0,0 +1,15 @@
+package atime
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fromInfo(info os.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Atim.Unix()), true
+}
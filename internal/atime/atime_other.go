@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package atime
+
+import (
+	"os"
+	"time"
+)
+
+func fromInfo(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,31 @@
+package atime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := os.Chtimes(path, want, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := Of(path)
+	if !ok {
+		t.Skip("access times are not available on this platform")
+	}
+	if !got.Equal(want) {
+		t.Errorf("Of() = %v, want %v", got, want)
+	}
+
+	if _, ok := Of(filepath.Join(t.TempDir(), "missing")); ok {
+		t.Error("Of() reported a time for a missing file")
+	}
+}
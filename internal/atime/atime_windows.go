@@ -0,0 +1,15 @@
+package atime
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fromInfo(info os.FileInfo) (time.Time, bool) {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, data.LastAccessTime.Nanoseconds()), true
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Command is the command an alias runs. In exec mode Argv is the program and
+// its arguments, run without a shell. In shell mode Argv holds a single
+// command line that the wrapper hands to the shell unchanged.
+type Command struct {
+	Argv  []string          `json:"argv"`
+	Shell bool              `json:"shell,omitempty"`
+	Env   map[string]string `json:"env,omitempty"`
+	Dir   string            `json:"dir,omitempty"`
+}
+
+// shellMetachars are characters that only mean something to a shell; a command
+// line containing any of them outside single quotes is kept in shell mode
+const shellMetachars = "|&;<>()$`*?[#"
+
+// ParseCommand splits a command line into a Command. Quotes and backslashes
+// are handled the way a POSIX shell would. Lines that rely on pipes,
+// substitutions, globbing or other shell syntax are stored in shell mode.
+func ParseCommand(line string) *Command {
+	line = strings.TrimSpace(line)
+	argv, ok := splitWords(line)
+	if !ok || len(argv) == 0 || isAssignment(argv[0]) {
+		return &Command{Argv: []string{line}, Shell: true}
+	}
+	return &Command{Argv: argv}
+}
+
+// NewCommand returns an exec-mode command for argv
+func NewCommand(argv ...string) *Command {
+	return &Command{Argv: append([]string(nil), argv...)}
+}
+
+// Program returns the executable the command runs, or the command line in shell mode
+func (c *Command) Program() string {
+	if len(c.Argv) == 0 {
+		return ""
+	}
+	return c.Argv[0]
+}
+
+// String renders the command as a single shell-quoted line
+func (c *Command) String() string {
+	if c.Shell {
+		return strings.Join(c.Argv, " ")
+	}
+
+	quoted := make([]string, len(c.Argv))
+	for i, arg := range c.Argv {
+		quoted[i] = QuoteWord(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// EnvKeys returns the names of the environment variables in sorted order
+func (c *Command) EnvKeys() []string {
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// QuoteWord quotes s for a POSIX shell if it needs quoting
+func QuoteWord(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\"+shellMetachars+"~{}!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isAssignment reports whether word is a NAME=value prefix, which only a shell understands
+func isAssignment(word string) bool {
+	name, _, found := strings.Cut(word, "=")
+	if !found || name == "" {
+		return false
+	}
+	for i, char := range name {
+		if char != '_' && !(char >= 'a' && char <= 'z') && !(char >= 'A' && char <= 'Z') && (i == 0 || !(char >= '0' && char <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords splits a line into words the way a POSIX shell would. It returns
+// false when the line uses shell syntax that cannot be represented as argv.
+func splitWords(line string) ([]string, bool) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+
+	for i := 0; i < len(line); i++ {
+		char := line[i]
+		switch {
+		case char == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, false
+			}
+			current.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case char == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				switch {
+				case line[i] == '$' || line[i] == '`':
+					return nil, false
+				case line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0:
+					i++
+				}
+				current.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, false
+			}
+			inWord = true
+		case char == '\\':
+			if i+1 >= len(line) {
+				return nil, false
+			}
+			i++
+			current.WriteByte(line[i])
+			inWord = true
+		case char == ' ' || char == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case char == '\n' || strings.IndexByte(shellMetachars, char) >= 0:
+			return nil, false
+		case char == '~' && !inWord:
+			return nil, false
+		default:
+			current.WriteByte(char)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, true
+}
@@ -2,24 +2,133 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"lnb/internal/fsops"
 )
 
-// LnbEntry represents a single installed binary
+// CurrentVersion is the config schema version written by Save
+const CurrentVersion = "2"
+
+// ErrCorrupt is returned by Load when the config file cannot be parsed
+var ErrCorrupt = errors.New("config file is corrupt")
+
+// Kind says what an entry is
+type Kind string
+
+const (
+	KindBinary    Kind = "binary"    // an existing executable made available on PATH
+	KindAlias     Kind = "alias"     // a wrapper that runs a command line
+	KindScript    Kind = "script"    // a script file managed by lnb
+	KindShim      Kind = "shim"      // a generated launcher for a non-native executable
+	KindContainer Kind = "container" // a wrapper that runs an image with docker or podman
+	KindLocal     Kind = "local"     // a wrapper that runs the nearest project's definition of its name
+)
+
+// Mode says how an entry's target was created
+type Mode string
+
+const (
+	ModeSymlink Mode = "symlink" // target is a symlink to the source
+	ModeWrapper Mode = "wrapper" // target is a generated script
+)
+
+// Origin says how an entry got into the config
+type Origin string
+
+const (
+	OriginLocal    Origin = "local"    // created on this machine
+	OriginMigrated Origin = "migrated" // converted from an older config file
+	OriginImported Origin = "imported" // recreated from a bundle made by 'lnb export'
+)
+
+// ShimStyle says which launchers are generated for an entry on Windows
+type ShimStyle string
+
+const (
+	ShimCmd  ShimStyle = "cmd"  // a .cmd (binaries) or .bat (aliases) file; the default
+	ShimPS1  ShimStyle = "ps1"  // a PowerShell script only
+	ShimBoth ShimStyle = "both" // both of the above
+)
+
+// ParseShimStyle checks a shim style given by the user; empty means ShimCmd
+func ParseShimStyle(s string) (ShimStyle, error) {
+	switch style := ShimStyle(strings.ToLower(s)); style {
+	case "":
+		return ShimCmd, nil
+	case ShimCmd, ShimPS1, ShimBoth:
+		return style, nil
+	}
+	return "", fmt.Errorf("unknown shim style '%s' (want cmd, ps1 or both)", s)
+}
+
+// LnbEntry represents a single installed binary or alias
 type LnbEntry struct {
-	Name        string    `json:"name"`
-	SourcePath  string    `json:"source_path"`
-	TargetPath  string    `json:"target_path"`
-	InstalledAt time.Time `json:"installed_at"`
+	Name         string              `json:"name"`
+	Kind         Kind                `json:"kind"`
+	SourcePath   string              `json:"source_path,omitempty"` // binaries only
+	Command      *Command            `json:"command,omitempty"`     // aliases only
+	Container    *Container          `json:"container,omitempty"`   // container aliases only
+	Fallback     *Command            `json:"fallback,omitempty"`    // local aliases only: what runs outside projects defining it
+	Variants     map[string]*Command `json:"variants,omitempty"`    // aliases only: what runs instead of Command, keyed by GOOS
+	Literal      bool                `json:"literal,omitempty"`     // aliases stored exactly as given
+	TargetPath   string              `json:"target_path"`
+	Mode         Mode                `json:"mode,omitempty"`
+	Origin       Origin              `json:"origin,omitempty"`
+	Description  string              `json:"description,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	Shim         ShimStyle           `json:"shim,omitempty"`          // Windows only
+	PosixShim    bool                `json:"posix_shim,omitempty"`    // Windows only
+	Desktop      bool                `json:"desktop,omitempty"`       // Linux only
+	ExtraTargets []string            `json:"extra_targets,omitempty"` // further generated files, e.g. a .ps1 next to a .bat
+	InstalledAt  time.Time           `json:"installed_at"`
+	UpdatedAt    *time.Time          `json:"updated_at,omitempty"` // last lnb edit, nil if never changed
 }
 
 // Config represents the LNB configuration
 type Config struct {
-	Entries map[string]*LnbEntry `json:"entries"`
-	Version string               `json:"version"`
+	Entries  map[string]*LnbEntry `json:"entries"`            // the entries of the profile in use
+	BinDir   string               `json:"bin_dir,omitempty"`  // overrides the OS default bin directory
+	Profile  string               `json:"profile,omitempty"`  // the profile in use; empty means DefaultProfile
+	Profiles map[string]*Profile  `json:"profiles,omitempty"` // created profiles and the entries of those not in use
+	Trusted  map[string]string    `json:"trusted,omitempty"`  // project files local aliases may run, with the fingerprint they were trusted at
+	Version  string               `json:"version"`
+}
+
+// IsAlias reports whether the entry is a wrapper lnb writes for a command
+// rather than a binary: an alias, container alias or local alias
+func (e *LnbEntry) IsAlias() bool {
+	return e.Kind == KindAlias || e.Kind == KindContainer || e.Kind == KindLocal
+}
+
+// VariantOSes are the operating systems an alias can have a command variant for
+var VariantOSes = []string{"darwin", "linux", "windows"}
+
+// CommandFor returns the command an alias runs on goos: its variant for goos
+// if it has one, else its own command
+func (e *LnbEntry) CommandFor(goos string) *Command {
+	if variant, ok := e.Variants[goos]; ok {
+		return variant
+	}
+	return e.Command
+}
+
+// Trust records that the project file at path may be run as it is now
+func (c *Config) Trust(path, sum string) {
+	if c.Trusted == nil {
+		c.Trusted = make(map[string]string)
+	}
+	c.Trusted[path] = sum
+}
+
+// IsTrusted reports whether the project file at path was trusted with these contents
+func (c *Config) IsTrusted(path, sum string) bool {
+	return c.Trusted[path] == sum
 }
 
 // GetConfigPath returns the path to the config file
@@ -40,74 +149,120 @@ func GetConfigPath() (string, error) {
 	configFile := filepath.Join(configDir, "config.json")
 
 	// Ensure the config directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := fsops.MkdirAll(configDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create config directory: %v", err)
 	}
 
 	return configFile, nil
 }
 
-// Load reads the config file
+// DataPath returns the path of a file called name kept next to the config file
+func DataPath(name string) (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), name), nil
+}
+
+// newConfig returns an empty config at the current schema version
+func newConfig() *Config {
+	return &Config{
+		Entries: make(map[string]*LnbEntry),
+		Version: CurrentVersion,
+	}
+}
+
+// Load reads the config file, migrating it to the current schema if needed.
+// A file that cannot be parsed is never replaced; Load returns ErrCorrupt.
 func Load() (*Config, error) {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
-	// If file doesn't exist, return empty config
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return &Config{
-			Entries: make(map[string]*LnbEntry),
-			Version: "1.0",
-		}, nil
+	data, err := fsops.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return newConfig(), nil
 	}
-
-	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	config, migratedFrom, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v (fix or remove it)", ErrCorrupt, configPath, err)
+	}
+
+	if migratedFrom != "" {
+		// Keep the original around in case the migration got something wrong
+		backupPath := configPath + ".v" + migratedFrom + ".bak"
+		if err := fsops.WriteFile(backupPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to back up config before migration: %v", err)
+		}
+		if err := config.Save(); err != nil {
+			return nil, err
+		}
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-// Save writes the config file
+// Save writes the config file atomically
 func (c *Config) Save() error {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
 
+	snapshotIfDue(configPath)
+
+	c.Version = CurrentVersion
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %v", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := fsops.WriteAtomic(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %v", err)
 	}
 
 	return nil
 }
 
-// AddEntry adds a new entry to the config
-func (c *Config) AddEntry(name, sourcePath, targetPath string) {
+// AddBinary records a binary made available at targetPath
+func (c *Config) AddBinary(name, sourcePath, targetPath string, mode Mode) *LnbEntry {
+	return c.add(&LnbEntry{
+		Name:       name,
+		Kind:       KindBinary,
+		SourcePath: sourcePath,
+		TargetPath: targetPath,
+		Mode:       mode,
+	})
+}
+
+// AddAlias records an alias whose wrapper lives at targetPath
+func (c *Config) AddAlias(name string, command *Command, targetPath string) *LnbEntry {
+	return c.add(&LnbEntry{
+		Name:       name,
+		Kind:       KindAlias,
+		Command:    command,
+		TargetPath: targetPath,
+		Mode:       ModeWrapper,
+	})
+}
+
+// add stores a new entry, filling in the fields every entry shares
+func (c *Config) add(entry *LnbEntry) *LnbEntry {
 	// Initialize entries map if nil
 	if c.Entries == nil {
 		c.Entries = make(map[string]*LnbEntry)
 	}
 
-	// Add new entry
-	c.Entries[name] = &LnbEntry{
-		Name:        name,
-		SourcePath:  sourcePath,
-		TargetPath:  targetPath,
-		InstalledAt: time.Now(),
-	}
+	entry.Origin = OriginLocal
+	entry.InstalledAt = time.Now()
+	c.Entries[entry.Name] = entry
+	return entry
 }
 
 // RemoveEntry removes an entry from the config
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Container runtimes a container alias can use
+const (
+	RuntimeDocker = "docker"
+	RuntimePodman = "podman"
+)
+
+// DefaultWorkdir is where the current directory is mounted in the container
+const DefaultWorkdir = "/work"
+
+// Container describes what a container alias runs. The wrapper mounts the
+// current directory, passes the terminal through when there is one, runs as
+// the calling user on Linux and macOS, and forwards its arguments after Cmd.
+type Container struct {
+	Image   string   `json:"image"`
+	Runtime string   `json:"runtime"`            // docker or podman
+	Env     []string `json:"env,omitempty"`      // host variables passed through by name
+	Workdir string   `json:"workdir,omitempty"`  // mount point of the current dir, DefaultWorkdir if empty
+	RunArgs []string `json:"run_args,omitempty"` // extra options for "run", e.g. -p 8080:80
+	Cmd     []string `json:"cmd,omitempty"`      // command and arguments run in the image
+}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks a container spec before a wrapper is generated from it
+func (c *Container) Validate() error {
+	if strings.TrimSpace(c.Image) == "" {
+		return fmt.Errorf("image cannot be empty")
+	}
+	if strings.ContainsAny(c.Image, " \t\n\"'`$;&|<>%") {
+		return fmt.Errorf("invalid image name '%s'", c.Image)
+	}
+	if c.Runtime != RuntimeDocker && c.Runtime != RuntimePodman {
+		return fmt.Errorf("unknown container runtime '%s' (want docker or podman)", c.Runtime)
+	}
+	for _, name := range c.Env {
+		if !envName.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name '%s'", name)
+		}
+	}
+	if c.Workdir != "" && !strings.HasPrefix(c.Workdir, "/") {
+		return fmt.Errorf("workdir '%s' must be an absolute path inside the container", c.Workdir)
+	}
+	return nil
+}
+
+// Command returns the shell-mode command a wrapper runs for goos. The current
+// directory, terminal and user are looked up when the wrapper runs, not when
+// it is created.
+func (c *Container) Command(goos string) *Command {
+	workdir := c.Workdir
+	if workdir == "" {
+		workdir = DefaultWorkdir
+	}
+	if goos == "windows" {
+		return &Command{Argv: []string{c.line(windowsWord, `"%CD%:`+workdir+`"`, nil, workdir)}, Shell: true}
+	}
+
+	// Rootless podman maps the calling user into the container by itself
+	user := []string{`--user "$(id -u):$(id -g)"`}
+	if c.Runtime == RuntimePodman {
+		user = []string{"--userns=keep-id"}
+	}
+	runOpts := append([]string{`$([ -t 0 ] && [ -t 1 ] && echo -t)`}, user...)
+	return &Command{Argv: []string{c.line(QuoteWord, `"$PWD:`+workdir+`"`, runOpts, workdir)}, Shell: true}
+}
+
+// line renders the run command with quote for user-supplied words
+func (c *Container) line(quote func(string) string, mount string, runOpts []string, workdir string) string {
+	words := []string{c.Runtime, "run", "--rm", "-i"}
+	words = append(words, runOpts...)
+	words = append(words, "-v", mount, "-w", quote(workdir))
+	for _, name := range c.Env {
+		words = append(words, "-e", name)
+	}
+	for _, arg := range c.RunArgs {
+		words = append(words, quote(arg))
+	}
+	words = append(words, quote(c.Image))
+	for _, arg := range c.Cmd {
+		words = append(words, quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// windowsWord quotes a word for a cmd.exe command line if it needs quoting
+func windowsWord(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t&|<>^()\",;=") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
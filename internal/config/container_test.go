@@ -0,0 +1,54 @@
+package config
+
+import "testing"
+
+func TestContainerCommand(t *testing.T) {
+	spec := &Container{
+		Image:   "node:20",
+		Runtime: RuntimeDocker,
+		Env:     []string{"NPM_TOKEN", "CI"},
+		RunArgs: []string{"-p", "3000:3000"},
+		Cmd:     []string{"npm", "run", "dev server"},
+	}
+
+	testCases := []struct {
+		goos    string
+		runtime string
+		want    string
+	}{
+		{"linux", RuntimeDocker, `docker run --rm -i $([ -t 0 ] && [ -t 1 ] && echo -t) --user "$(id -u):$(id -g)" -v "$PWD:/work" -w /work -e NPM_TOKEN -e CI -p 3000:3000 node:20 npm run 'dev server'`},
+		{"darwin", RuntimePodman, `podman run --rm -i $([ -t 0 ] && [ -t 1 ] && echo -t) --userns=keep-id -v "$PWD:/work" -w /work -e NPM_TOKEN -e CI -p 3000:3000 node:20 npm run 'dev server'`},
+		{"windows", RuntimeDocker, `docker run --rm -i -v "%CD%:/work" -w /work -e NPM_TOKEN -e CI -p 3000:3000 node:20 npm run "dev server"`},
+	}
+
+	for _, tc := range testCases {
+		spec.Runtime = tc.runtime
+		command := spec.Command(tc.goos)
+		if !command.Shell || len(command.Argv) != 1 {
+			t.Fatalf("Command(%s) = %+v, want a single shell-mode line", tc.goos, command)
+		}
+		if command.Argv[0] != tc.want {
+			t.Errorf("Command(%s, %s):\n got %s\nwant %s", tc.goos, tc.runtime, command.Argv[0], tc.want)
+		}
+	}
+}
+
+func TestContainerValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		spec  Container
+		valid bool
+	}{
+		{"ok", Container{Image: "alpine", Runtime: RuntimeDocker}, true},
+		{"no image", Container{Runtime: RuntimeDocker}, false},
+		{"image injection", Container{Image: "alpine; rm -rf /", Runtime: RuntimeDocker}, false},
+		{"unknown runtime", Container{Image: "alpine", Runtime: "lxc"}, false},
+		{"bad env name", Container{Image: "alpine", Runtime: RuntimePodman, Env: []string{"A-B"}}, false},
+		{"relative workdir", Container{Image: "alpine", Runtime: RuntimePodman, Workdir: "src"}, false},
+	}
+	for _, tc := range testCases {
+		if err := tc.spec.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: Validate() = %v, want valid=%v", tc.name, err, tc.valid)
+		}
+	}
+}
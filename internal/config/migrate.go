@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// legacyEntry is an entry as written by config version 1.0, where aliases were
+// marked by an "alias:" prefix on SourcePath
+type legacyEntry struct {
+	Name        string    `json:"name"`
+	SourcePath  string    `json:"source_path"`
+	TargetPath  string    `json:"target_path"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// decode parses a config file of any known version. It returns the schema
+// version the file was migrated from, or "" if it was already current.
+func decode(data []byte) (*Config, string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return newConfig(), "", nil
+	}
+
+	// The earliest releases wrote a bare array of entries
+	if trimmed[0] == '[' {
+		var legacy []*legacyEntry
+		if err := json.Unmarshal(trimmed, &legacy); err != nil {
+			return nil, "", err
+		}
+		return migrateLegacy(legacy), "1", nil
+	}
+
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return nil, "", err
+	}
+
+	switch header.Version {
+	case "", "1.0":
+		var legacy struct {
+			Entries map[string]*legacyEntry `json:"entries"`
+		}
+		if err := json.Unmarshal(trimmed, &legacy); err != nil {
+			return nil, "", err
+		}
+		entries := make([]*legacyEntry, 0, len(legacy.Entries))
+		for _, entry := range legacy.Entries {
+			entries = append(entries, entry)
+		}
+		return migrateLegacy(entries), "1", nil
+	}
+
+	config := newConfig()
+	if err := json.Unmarshal(trimmed, config); err != nil {
+		return nil, "", err
+	}
+	if config.Entries == nil {
+		config.Entries = make(map[string]*LnbEntry)
+	}
+	return config, "", nil
+}
+
+// migrateLegacy converts version 1.0 entries to the current schema
+func migrateLegacy(legacy []*legacyEntry) *Config {
+	config := newConfig()
+
+	for _, old := range legacy {
+		if old == nil || old.Name == "" {
+			continue
+		}
+
+		entry := &LnbEntry{
+			Name:        old.Name,
+			TargetPath:  old.TargetPath,
+			Origin:      OriginMigrated,
+			InstalledAt: old.InstalledAt,
+		}
+
+		if command, isAlias := strings.CutPrefix(old.SourcePath, "alias:"); isAlias {
+			entry.Kind = KindAlias
+			entry.Command = ParseCommand(command)
+			entry.Mode = ModeWrapper
+		} else {
+			entry.Kind = KindBinary
+			entry.SourcePath = old.SourcePath
+			entry.Mode = ModeSymlink
+			if strings.EqualFold(filepath.Ext(old.TargetPath), ".cmd") {
+				entry.Mode = ModeWrapper
+			}
+		}
+
+		config.Entries[entry.Name] = entry
+	}
+
+	return config
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultProfile is the profile entries belong to until another one is used
+const DefaultProfile = "default"
+
+// Profile is a named set of entries that can be switched on and off. Only one
+// profile is in use at a time; its entries live in Config.Entries.
+type Profile struct {
+	BinDir  string               `json:"bin_dir,omitempty"` // overrides Config.BinDir while the profile is in use
+	Entries map[string]*LnbEntry `json:"entries,omitempty"` // the profile's entries while it is not in use
+}
+
+// ValidateProfileName checks a profile name: 1 to 64 letters, digits, '.', '_' or '-'
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("invalid profile name '%s': at most 64 characters are allowed", name)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return fmt.Errorf("invalid profile name '%s': profile names may only contain letters, digits, '.', '_' and '-'", name)
+		}
+	}
+	return nil
+}
+
+// ActiveProfile returns the name of the profile in use
+func (c *Config) ActiveProfile() string {
+	if c.Profile == "" {
+		return DefaultProfile
+	}
+	return c.Profile
+}
+
+// ProfileNames returns every profile, the default one included, sorted by name
+func (c *Config) ProfileNames() []string {
+	names := []string{DefaultProfile}
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile finds a profile by name. The default profile always exists.
+func (c *Config) GetProfile(name string) (*Profile, bool) {
+	if profile, exists := c.Profiles[name]; exists {
+		return profile, true
+	}
+	if name == DefaultProfile {
+		return &Profile{}, true
+	}
+	return nil, false
+}
+
+// AddProfile creates an empty profile whose entries go to binDir, or to the
+// usual bin directory when binDir is empty
+func (c *Config) AddProfile(name, binDir string) (*Profile, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	if _, exists := c.GetProfile(name); exists {
+		return nil, fmt.Errorf("profile '%s' already exists", name)
+	}
+	return c.profile(name, binDir), nil
+}
+
+// RemoveProfile deletes a profile that is not in use, along with its entries
+func (c *Config) RemoveProfile(name string) {
+	if name != c.ActiveProfile() {
+		delete(c.Profiles, name)
+	}
+}
+
+// SetProfileBinDir sets the bin directory of a profile; empty means the usual one
+func (c *Config) SetProfileBinDir(name, binDir string) {
+	c.profile(name, "").BinDir = binDir
+}
+
+// ProfileEntries returns the entries of a profile, whether it is in use or not
+func (c *Config) ProfileEntries(name string) []*LnbEntry {
+	if name == c.ActiveProfile() {
+		return c.List()
+	}
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return []*LnbEntry{}
+	}
+	entries := make([]*LnbEntry, 0, len(profile.Entries))
+	for _, entry := range profile.Entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ActiveBinDir returns the bin directory set for the profile in use, falling
+// back to BinDir. Empty means the OS default.
+func (c *Config) ActiveBinDir() string {
+	if profile, exists := c.Profiles[c.ActiveProfile()]; exists && profile.BinDir != "" {
+		return profile.BinDir
+	}
+	return c.BinDir
+}
+
+// SwapProfile puts the entries in use away in the active profile and makes
+// name the active profile. It only changes the config: Entries is left empty
+// and the entries of the new profile are returned so that their files can be
+// created, which adds them back.
+func (c *Config) SwapProfile(name string) []*LnbEntry {
+	c.profile(c.ActiveProfile(), "").Entries = c.Entries
+
+	next := c.profile(name, "")
+	entries := make([]*LnbEntry, 0, len(next.Entries))
+	for _, entry := range next.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	next.Entries = nil
+
+	c.Entries = make(map[string]*LnbEntry)
+	c.Profile = name
+	if name == DefaultProfile {
+		// The default profile needs no record of its own while it is in use
+		c.Profile = ""
+		if next.BinDir == "" {
+			delete(c.Profiles, name)
+		}
+	}
+	return entries
+}
+
+// profile returns the stored profile called name, creating it if needed
+func (c *Config) profile(name, binDir string) *Profile {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	profile, exists := c.Profiles[name]
+	if !exists {
+		profile = &Profile{BinDir: binDir}
+		c.Profiles[name] = profile
+	}
+	return profile
+}
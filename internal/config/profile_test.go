@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSwapProfile(t *testing.T) {
+	cfg := newConfig()
+	cfg.BinDir = "/shared/bin"
+	cfg.AddAlias("deploy", ParseCommand("echo default"), "/shared/bin/deploy")
+	if _, err := cfg.AddProfile("clienta", "/clienta/bin"); err != nil {
+		t.Fatalf("AddProfile: %v", err)
+	}
+	if _, err := cfg.AddProfile("clienta", ""); err == nil {
+		t.Error("AddProfile should refuse an existing profile")
+	}
+	if _, err := cfg.AddProfile("bad name", ""); err == nil {
+		t.Error("AddProfile should refuse an invalid name")
+	}
+
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"clienta", "default"}) {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+
+	if entries := cfg.SwapProfile("clienta"); len(entries) != 0 {
+		t.Errorf("SwapProfile returned %d entries for an empty profile", len(entries))
+	}
+	if cfg.ActiveProfile() != "clienta" || cfg.ActiveBinDir() != "/clienta/bin" || len(cfg.Entries) != 0 {
+		t.Errorf("after switching: profile %q, bin dir %q, %d entries", cfg.ActiveProfile(), cfg.ActiveBinDir(), len(cfg.Entries))
+	}
+	cfg.AddAlias("deploy", ParseCommand("echo clienta"), "/clienta/bin/deploy")
+
+	if got := cfg.ProfileEntries(DefaultProfile); len(got) != 1 || got[0].Command.String() != "echo default" {
+		t.Errorf("ProfileEntries(default) = %v", got)
+	}
+
+	entries := cfg.SwapProfile(DefaultProfile)
+	if len(entries) != 1 || entries[0].Command.String() != "echo default" {
+		t.Fatalf("SwapProfile(default) returned %v", entries)
+	}
+	if cfg.Profile != "" || cfg.ActiveBinDir() != "/shared/bin" {
+		t.Errorf("after switching back: profile %q, bin dir %q", cfg.Profile, cfg.ActiveBinDir())
+	}
+	if got := cfg.ProfileEntries("clienta"); len(got) != 1 || got[0].Command.String() != "echo clienta" {
+		t.Errorf("ProfileEntries(clienta) = %v", got)
+	}
+
+	cfg.RemoveProfile(DefaultProfile)
+	if _, exists := cfg.GetProfile(DefaultProfile); !exists {
+		t.Error("RemoveProfile removed the profile in use")
+	}
+	cfg.RemoveProfile("clienta")
+	if _, exists := cfg.GetProfile("clienta"); exists {
+		t.Error("RemoveProfile kept clienta")
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"lnb/internal/fsops"
+)
+
+// SnapshotInterval is how often Save keeps a copy of the config it replaces
+const SnapshotInterval = 24 * time.Hour
+
+// KeepSnapshots is how many snapshots are kept; older ones are deleted
+const KeepSnapshots = 14
+
+// snapshotLayout names snapshots by the time they were taken
+const snapshotLayout = "20060102-150405"
+
+// Snapshot is a copy of the config file kept in the snapshots directory next to it
+type Snapshot struct {
+	Name string // when it was taken, e.g. 20240131-093000
+	Path string
+	Time time.Time
+}
+
+// Snapshots returns the snapshots kept, newest first
+func Snapshots() ([]*Snapshot, error) {
+	dir, err := DataPath("snapshots")
+	if err != nil {
+		return nil, err
+	}
+	files, err := fsops.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "config-"), ".json")
+		taken, err := time.ParseInLocation(snapshotLayout, name, time.Local)
+		if err != nil || file.IsDir() {
+			continue
+		}
+		snapshots = append(snapshots, &Snapshot{Name: name, Path: filepath.Join(dir, file.Name()), Time: taken})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.After(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// TakeSnapshot copies the config file as it is on disk now and deletes the
+// snapshots beyond KeepSnapshots
+func TakeSnapshot() (*Snapshot, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := fsops.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		data, err = []byte("{}\n"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	return writeSnapshot(data)
+}
+
+// LoadSnapshot reads the snapshot called name as a config
+func LoadSnapshot(name string) (*Config, error) {
+	snapshots, err := Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			data, err := fsops.ReadFile(snapshot.Path)
+			if err != nil {
+				return nil, err
+			}
+			config, _, err := decode(data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, snapshot.Path, err)
+			}
+			return config, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no snapshot called '%s'", name)
+}
+
+// snapshotIfDue keeps a copy of the config file Save is about to replace when
+// the newest snapshot is older than SnapshotInterval. It is best effort: a
+// snapshot that cannot be taken never stops the config from being saved.
+func snapshotIfDue(configPath string) {
+	data, err := fsops.ReadFile(configPath)
+	if err != nil {
+		return
+	}
+	snapshots, err := Snapshots()
+	if err != nil || (len(snapshots) > 0 && time.Since(snapshots[0].Time) < SnapshotInterval) {
+		return
+	}
+	writeSnapshot(data)
+}
+
+// writeSnapshot stores data as a new snapshot and prunes old ones
+func writeSnapshot(data []byte) (*Snapshot, error) {
+	dir, err := DataPath("snapshots")
+	if err != nil {
+		return nil, err
+	}
+	if err := fsops.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	// Names have a resolution of a second, so a snapshot taken in the same
+	// second as the newest one is named a second after it
+	now := time.Now().Truncate(time.Second)
+	if snapshots, err := Snapshots(); err == nil && len(snapshots) > 0 && !snapshots[0].Time.Before(now) {
+		now = snapshots[0].Time.Add(time.Second)
+	}
+	path := filepath.Join(dir, "config-"+now.Format(snapshotLayout)+".json")
+	if err := fsops.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %v", err)
+	}
+
+	snapshots, err := Snapshots()
+	if err == nil && len(snapshots) > KeepSnapshots {
+		for _, old := range snapshots[KeepSnapshots:] {
+			fsops.Remove(old.Path)
+		}
+	}
+	return &Snapshot{Name: now.Format(snapshotLayout), Path: path, Time: now}, nil
+}
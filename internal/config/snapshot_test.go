@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	t.Setenv("LNB_TEST_CONFIG_DIR", t.TempDir())
+
+	cfg := newConfig()
+	cfg.AddAlias("deploy", ParseCommand("echo one"), "/bin/deploy")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if snapshots, err := Snapshots(); err != nil || len(snapshots) != 0 {
+		t.Fatalf("the first save should not snapshot a missing config, got %v, %v", snapshots, err)
+	}
+
+	// The next save keeps the config it replaces, but only once per interval
+	cfg.AddAlias("deploy", ParseCommand("echo two"), "/bin/deploy")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	cfg.AddAlias("deploy", ParseCommand("echo three"), "/bin/deploy")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	snapshots, err := Snapshots()
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("Snapshots() = %v, %v, want one", snapshots, err)
+	}
+	saved, err := LoadSnapshot(snapshots[0].Name)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if entry, _ := saved.GetEntry("deploy"); entry == nil || entry.Command.String() != "echo one" {
+		t.Errorf("the snapshot holds %v, want the config before the second save", entry)
+	}
+
+	// Snapshots taken by hand never overwrite each other
+	taken, err := TakeSnapshot()
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	if taken.Name == snapshots[0].Name {
+		t.Errorf("TakeSnapshot reused the name %s", taken.Name)
+	}
+	if _, err := LoadSnapshot("19990101-000000"); err == nil {
+		t.Error("LoadSnapshot should fail for a missing snapshot")
+	}
+
+	// Only the newest KeepSnapshots are kept
+	for i := 0; i < KeepSnapshots; i++ {
+		if _, err := writeSnapshot([]byte("{}")); err != nil {
+			t.Fatalf("writeSnapshot: %v", err)
+		}
+	}
+	snapshots, err = Snapshots()
+	if err != nil || len(snapshots) != KeepSnapshots {
+		t.Errorf("kept %d snapshots, want %d (%v)", len(snapshots), KeepSnapshots, err)
+	}
+	if _, err := os.Stat(taken.Path); !os.IsNotExist(err) {
+		t.Errorf("the oldest snapshots should have been deleted")
+	}
+	if !snapshots[0].Time.After(time.Now().Add(-time.Minute)) {
+		t.Errorf("the newest snapshot is from %v", snapshots[0].Time)
+	}
+}
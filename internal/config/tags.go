@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormalizeTags splits comma-separated tags, trims and lowercases them, and
+// returns them sorted without duplicates. Tags may contain letters, digits,
+// '.', '_', '-' and '/', so they survive being typed on any shell.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, list := range tags {
+		for _, tag := range strings.Split(list, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			for _, char := range tag {
+				if !(char >= 'a' && char <= 'z') && !(char >= '0' && char <= '9') && !strings.ContainsRune("._-/", char) {
+					return nil, fmt.Errorf("invalid tag '%s': tags may only contain letters, digits, '.', '_', '-' and '/'", tag)
+				}
+			}
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// HasTag reports whether the entry is tagged tag
+func (e *LnbEntry) HasTag(tag string) bool {
+	tag = strings.ToLower(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{"Infra, team/web", "infra", " ", "old-project"})
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	want := []string{"infra", "old-project", "team/web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"has space", "semi;colon", "ünicode"} {
+		if _, err := NormalizeTags([]string{bad}); err == nil {
+			t.Errorf("NormalizeTags(%q) should fail", bad)
+		}
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	entry := &LnbEntry{Tags: []string{"infra"}}
+	if !entry.HasTag("infra") || !entry.HasTag("INFRA") || entry.HasTag("web") {
+		t.Errorf("HasTag gave the wrong answer for %v", entry.Tags)
+	}
+}
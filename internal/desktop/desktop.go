@@ -0,0 +1,292 @@
+// Package desktop writes freedesktop.org .desktop entries so GUI programs and
+// AppImages installed by lnb show up in Linux application menus.
+package desktop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lnb/internal/fsops"
+)
+
+// extractTimeout bounds how long an AppImage may take to unpack its icon
+const extractTimeout = 10 * time.Second
+
+// Entry is what goes into a .desktop file
+type Entry struct {
+	Name string // shown in menus
+	Exec string // program to run
+	Icon string // icon path or theme icon name
+}
+
+// dataHome returns $XDG_DATA_HOME, or ~/.local/share
+func dataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get home directory: %v", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// Path returns where the .desktop file for the lnb entry name is written. The
+// lnb- prefix keeps it from clobbering files installed by packages.
+func Path(name string) (string, error) {
+	data, err := dataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(data, "applications", "lnb-"+name+".desktop"), nil
+}
+
+// iconPath returns where an icon extracted for name is stored
+func iconPath(name, ext string) (string, error) {
+	data, err := dataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(data, "icons", "lnb", name+ext), nil
+}
+
+// IsAppImage reports whether path is an AppImage, by extension or by the
+// AppImage magic bytes at offset 8
+func IsAppImage(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".AppImage") {
+		return true
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, 11)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(header[8:11], []byte("AI\x02"))
+}
+
+// DisplayName turns an entry name such as obsidian or Obsidian.AppImage into
+// the name shown in menus
+func DisplayName(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".AppImage") {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// Render returns the contents of a .desktop file for entry
+func Render(entry Entry) string {
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Type=Application\n")
+	fmt.Fprintf(&b, "Name=%s\n", escapeValue(entry.Name))
+	fmt.Fprintf(&b, "Exec=%s %%U\n", escapeValue(quoteExec(entry.Exec)))
+	if entry.Icon != "" {
+		fmt.Fprintf(&b, "Icon=%s\n", escapeValue(entry.Icon))
+	}
+	b.WriteString("Terminal=false\n")
+	b.WriteString("Comment=Installed by lnb\n")
+	return b.String()
+}
+
+// quoteExec quotes a program path for the Exec key: reserved characters need
+// double quotes, inside which " ` $ and \ are backslash-escaped, and a
+// literal % is written %%
+func quoteExec(path string) string {
+	path = strings.ReplaceAll(path, "%", "%%")
+	if !strings.ContainsAny(path, " \t\n\"'\\><~|&;$*?#()`") {
+		return path
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range path {
+		if strings.ContainsRune("\"`$\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// escapeValue applies the escapes every .desktop string value needs
+func escapeValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+}
+
+// Install writes the .desktop file for name, which runs execPath, and finds
+// an icon for source. It returns every file it created, the .desktop file
+// first, and warnings for anything that did not work out but was not fatal.
+func Install(name, execPath, source string) ([]string, []string, error) {
+	desktopPath, err := Path(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := fsops.Stat(desktopPath); err == nil {
+		return nil, nil, fmt.Errorf("desktop entry %s already exists", desktopPath)
+	}
+
+	var created, warnings []string
+	icon, extracted, err := findIcon(name, source)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("no icon for '%s': %v", name, err))
+	}
+	if extracted {
+		created = append(created, icon)
+	}
+	if icon == "" {
+		icon = "application-x-executable"
+	}
+
+	if err := fsops.MkdirAll(filepath.Dir(desktopPath), 0755); err != nil {
+		return nil, nil, fmt.Errorf("error creating applications dir: %v", err)
+	}
+	entry := Entry{Name: DisplayName(name), Exec: execPath, Icon: icon}
+	if err := fsops.WriteFile(desktopPath, []byte(Render(entry)), 0644); err != nil {
+		for _, path := range created {
+			fsops.Remove(path)
+		}
+		return nil, nil, fmt.Errorf("failed to write desktop entry: %v", err)
+	}
+	refreshDatabase(filepath.Dir(desktopPath))
+
+	return append([]string{desktopPath}, created...), warnings, nil
+}
+
+// Removed refreshes the menu cache after a .desktop file lnb wrote is deleted
+func Removed(desktopPath string) {
+	refreshDatabase(filepath.Dir(desktopPath))
+}
+
+// refreshDatabase asks desktop environments to pick up changes, if the tool
+// for it is installed; menus also notice on their own, just more slowly
+func refreshDatabase(dir string) {
+	if fsops.Planning() {
+		return
+	}
+	if tool, err := exec.LookPath("update-desktop-database"); err == nil {
+		exec.Command(tool, "-q", dir).Run()
+	}
+}
+
+// findIcon looks for an icon for source: one extracted from an AppImage, or
+// an image next to the program. extracted says whether a file was created.
+func findIcon(name, source string) (icon string, extracted bool, err error) {
+	if IsAppImage(source) {
+		icon, err := extractAppImageIcon(name, source)
+		if err == nil {
+			return icon, true, nil
+		}
+		return "", false, err
+	}
+
+	dir := filepath.Dir(source)
+	base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	for _, candidate := range []string{base + ".svg", base + ".png", "icon.svg", "icon.png"} {
+		path := filepath.Join(dir, candidate)
+		if _, err := os.Stat(path); err == nil {
+			return path, false, nil
+		}
+	}
+	return "", false, nil
+}
+
+// appImageExtract unpacks the files matching pattern from an AppImage into dir/squashfs-root
+func appImageExtract(dir, appImage, pattern string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), extractTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, appImage, "--appimage-extract", pattern)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("extracting the AppImage icon failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// extractAppImageIcon unpacks the .DirIcon every AppImage carries and copies
+// it to lnb's icon directory
+func extractAppImageIcon(name, appImage string) (string, error) {
+	if fsops.Planning() {
+		return planAppImageIcon(name, appImage)
+	}
+
+	tmp, err := os.MkdirTemp("", "lnb-appimage-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	dirIcon := filepath.Join(tmp, "squashfs-root", ".DirIcon")
+	if err := appImageExtract(tmp, appImage, ".DirIcon"); err != nil {
+		return "", err
+	}
+	// .DirIcon is usually a symlink to the real icon, which needs extracting too
+	if target, err := os.Readlink(dirIcon); err == nil {
+		target = filepath.Clean(target)
+		if filepath.IsAbs(target) || strings.HasPrefix(target, "..") {
+			return "", fmt.Errorf("the AppImage's .DirIcon points outside the image: %s", target)
+		}
+		if err := appImageExtract(tmp, appImage, target); err != nil {
+			return "", err
+		}
+	}
+
+	resolved, err := filepath.EvalSymlinks(dirIcon)
+	if err != nil {
+		return "", fmt.Errorf("the AppImage has no usable .DirIcon: %v", err)
+	}
+	root, err := filepath.EvalSymlinks(tmp)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("the AppImage's .DirIcon points outside the image")
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", fmt.Errorf("the AppImage has no usable .DirIcon: %v", err)
+	}
+
+	ext := ".png"
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("<svg")) || bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		ext = ".svg"
+	}
+	icon, err := iconPath(name, ext)
+	if err != nil {
+		return "", err
+	}
+	if err := fsops.MkdirAll(filepath.Dir(icon), 0755); err != nil {
+		return "", err
+	}
+	if err := fsops.WriteFile(icon, data, 0644); err != nil {
+		return "", err
+	}
+	return icon, nil
+}
+
+// planAppImageIcon notes the icon extractAppImageIcon would write without
+// running the AppImage, which is not done in a plan. The icon is assumed to
+// be a PNG, as it usually is.
+func planAppImageIcon(name, appImage string) (string, error) {
+	icon, err := iconPath(name, ".png")
+	if err != nil {
+		return "", err
+	}
+	if err := fsops.MkdirAll(filepath.Dir(icon), 0755); err != nil {
+		return "", err
+	}
+	op := fsops.Op{Action: fsops.OpWrite, Path: icon, Content: "(the icon packed in " + appImage + ")", Mode: 0644}
+	return icon, fsops.Change(op, func() error { return nil })
+}
@@ -0,0 +1,171 @@
+package desktop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lnb/internal/fsops"
+)
+
+func TestRender(t *testing.T) {
+	got := Render(Entry{Name: "My App", Exec: "/opt/my apps/run $HOME 100%", Icon: "/icons/app.png"})
+	want := `[Desktop Entry]
+Type=Application
+Name=My App
+Exec="/opt/my apps/run \\$HOME 100%%" %U
+Icon=/icons/app.png
+Terminal=false
+Comment=Installed by lnb
+`
+	if got != want {
+		t.Errorf("Render mismatch\n--- got ---\n%s--- want ---\n%s", got, want)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	testCases := map[string]string{
+		"obsidian":          "Obsidian",
+		"Obsidian.AppImage": "Obsidian",
+		"kdenlive.appimage": "Kdenlive",
+		"tool.sh":           "Tool.sh",
+	}
+	for name, want := range testCases {
+		if got := DisplayName(name); got != want {
+			t.Errorf("DisplayName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIsAppImage(t *testing.T) {
+	dir := t.TempDir()
+	magic := filepath.Join(dir, "tool")
+	if err := os.WriteFile(magic, []byte("\x7fELF\x02\x01\x01\x00AI\x02rest"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00rest"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsAppImage(magic) {
+		t.Errorf("IsAppImage did not recognise the AppImage magic bytes")
+	}
+	if IsAppImage(plain) {
+		t.Errorf("IsAppImage accepted a plain ELF binary")
+	}
+	if !IsAppImage(filepath.Join(dir, "missing.AppImage")) {
+		t.Errorf("IsAppImage did not go by the .AppImage extension")
+	}
+}
+
+// fakeAppImage mimics --appimage-extract: .DirIcon is a symlink to app.svg
+const fakeAppImage = `#!/bin/sh
+[ "$1" = --appimage-extract ] || exit 1
+mkdir -p squashfs-root
+case "$2" in
+    .DirIcon) ln -sf app.svg squashfs-root/.DirIcon ;;
+    app.svg) echo '<svg xmlns="http://www.w3.org/2000/svg"/>' > squashfs-root/app.svg ;;
+esac
+`
+
+func TestInstallExtractsAppImageIcon(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+
+	appImage := filepath.Join(t.TempDir(), "App.AppImage")
+	if err := os.WriteFile(appImage, []byte(fakeAppImage), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, warnings, err := Install("app", "/usr/local/bin/app", appImage)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	desktopPath := filepath.Join(data, "applications", "lnb-app.desktop")
+	iconPath := filepath.Join(data, "icons", "lnb", "app.svg")
+	if len(files) != 2 || files[0] != desktopPath || files[1] != iconPath {
+		t.Fatalf("Install created %v, want [%s %s]", files, desktopPath, iconPath)
+	}
+
+	contents, err := os.ReadFile(desktopPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"Name=App", "Exec=/usr/local/bin/app %U", "Icon=" + iconPath} {
+		if !strings.Contains(string(contents), line+"\n") {
+			t.Errorf("desktop file is missing %q:\n%s", line, contents)
+		}
+	}
+
+	if _, _, err := Install("app", "/usr/local/bin/app", appImage); err == nil {
+		t.Errorf("Install overwrote an existing desktop entry")
+	}
+}
+
+func TestInstallPlanDoesNotRunAppImage(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	appImage := filepath.Join(dir, "App.AppImage")
+	if err := os.WriteFile(appImage, []byte("#!/bin/sh\ntouch "+marker+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := fsops.Record(func() error {
+		_, _, err := Install("app", "/usr/local/bin/app", appImage)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("planning an install ran the AppImage")
+	}
+
+	icon := filepath.Join(data, "icons", "lnb", "app.png")
+	var planned []string
+	for _, op := range ops {
+		if op.Action == fsops.OpWrite {
+			planned = append(planned, op.Path)
+		}
+	}
+	if len(planned) != 2 || planned[0] != icon || planned[1] != filepath.Join(data, "applications", "lnb-app.desktop") {
+		t.Errorf("planned writes %v, want the icon %s and the desktop file", planned, icon)
+	}
+	if _, err := os.Stat(filepath.Join(data, "icons")); !os.IsNotExist(err) {
+		t.Errorf("planning an install created the icon directory")
+	}
+}
+
+func TestInstallUsesIconNextToBinary(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "viewer")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "viewer.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _, err := Install("viewer", binary, binary)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Install should only create the desktop file, created %v", files)
+	}
+	contents, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(contents), "Icon="+filepath.Join(dir, "viewer.png")+"\n") {
+		t.Errorf("desktop file does not use the icon next to the binary:\n%s", contents)
+	}
+}
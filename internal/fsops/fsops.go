@@ -0,0 +1,379 @@
+// Package fsops makes the file system changes lnb makes. While a plan is
+// being recorded, changes are noted instead of made, and reads through this
+// package see the noted changes as if they had been made, so code run for a
+// plan takes the same path it would take for real.
+package fsops
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Action is the kind of change an Op makes
+type Action string
+
+const (
+	OpMkdir   Action = "mkdir"
+	OpWrite   Action = "write"
+	OpAppend  Action = "append"
+	OpSymlink Action = "symlink"
+	OpRemove  Action = "remove"
+	OpRename  Action = "rename"   // Path moved to Target
+	OpAddPath Action = "add-path" // a directory added to the user's PATH outside any file
+)
+
+// Op is one change a plan found would be made
+type Op struct {
+	Action  Action
+	Path    string
+	Target  string      // what a symlink points at, or where a file is renamed to
+	Content string      // what is written or appended
+	Mode    fs.FileMode // permissions of a written file
+	Before  *string     // what a written file held before, nil if it did not exist
+}
+
+// node is a planned file, directory or symlink, or a removed path
+type node struct {
+	dir     bool
+	link    string
+	data    []byte
+	mode    fs.FileMode
+	removed bool
+}
+
+// recorder holds a plan being recorded
+type recorder struct {
+	ops   []Op
+	nodes map[string]*node // by clean path
+}
+
+// plan is the plan being recorded, nil when changes are made for real
+var plan *recorder
+
+// Record runs fn with changes noted instead of made and returns them in order.
+// It is not safe to use from more than one goroutine.
+func Record(fn func() error) ([]Op, error) {
+	if plan != nil {
+		return nil, errors.New("a plan is already being recorded")
+	}
+	plan = &recorder{nodes: make(map[string]*node)}
+	defer func() { plan = nil }()
+
+	err := fn()
+	return plan.ops, err
+}
+
+// Planning reports whether changes are being noted instead of made
+func Planning() bool {
+	return plan != nil
+}
+
+// MkdirAll creates a directory and its parents
+func MkdirAll(path string, perm fs.FileMode) error {
+	if plan == nil {
+		return os.MkdirAll(path, perm)
+	}
+	if info, err := Stat(path); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &fs.PathError{Op: "mkdir", Path: path, Err: syscall.ENOTDIR}
+	}
+	plan.note(Op{Action: OpMkdir, Path: path}, &node{dir: true})
+	return nil
+}
+
+// WriteFile writes data to path, creating it with perm if needed
+func WriteFile(path string, data []byte, perm fs.FileMode) error {
+	if plan == nil {
+		return os.WriteFile(path, data, perm)
+	}
+	if err := plan.checkParent("open", path); err != nil {
+		return err
+	}
+	op := Op{Action: OpWrite, Path: path, Content: string(data), Mode: perm}
+	if before, err := ReadFile(path); err == nil {
+		op.Before = new(string)
+		*op.Before = string(before)
+	}
+	plan.note(op, &node{data: data, mode: perm})
+	return nil
+}
+
+// WriteAtomic replaces path with data by writing a temporary file next to it
+// and renaming that over path, so that readers never see half a file
+func WriteAtomic(path string, data []byte, perm fs.FileMode) error {
+	if plan != nil {
+		return WriteFile(path, data, perm)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// AppendFile adds data to the end of path, creating it with perm if needed
+func AppendFile(path string, data []byte, perm fs.FileMode) error {
+	if plan == nil {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}
+	if err := plan.checkParent("open", path); err != nil {
+		return err
+	}
+	existing, err := ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	content := append(existing, data...)
+	plan.note(Op{Action: OpAppend, Path: path, Content: string(data), Mode: perm}, &node{data: content, mode: perm})
+	return nil
+}
+
+// Symlink creates path as a symbolic link to target
+func Symlink(target, path string) error {
+	if plan == nil {
+		return os.Symlink(target, path)
+	}
+	if _, err := Lstat(path); err == nil {
+		return &os.LinkError{Op: "symlink", Old: target, New: path, Err: fs.ErrExist}
+	}
+	if err := plan.checkParent("symlink", path); err != nil {
+		return err
+	}
+	plan.note(Op{Action: OpSymlink, Path: path, Target: target}, &node{link: target})
+	return nil
+}
+
+// Remove deletes a file or empty directory
+func Remove(path string) error {
+	if plan == nil {
+		return os.Remove(path)
+	}
+	if _, err := Lstat(path); err != nil {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	plan.note(Op{Action: OpRemove, Path: path}, &node{removed: true})
+	return nil
+}
+
+// Rename moves the file or symlink at oldPath to newPath, replacing what is
+// there
+func Rename(oldPath, newPath string) error {
+	if plan == nil {
+		return os.Rename(oldPath, newPath)
+	}
+	info, err := Lstat(oldPath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: fs.ErrNotExist}
+	}
+	if err := plan.checkParent("rename", newPath); err != nil {
+		return err
+	}
+
+	moved := &node{mode: info.Mode().Perm()}
+	switch {
+	case info.IsDir():
+		moved.dir = true
+	case info.Mode()&fs.ModeSymlink != 0:
+		if moved.link, err = Readlink(oldPath); err != nil {
+			return err
+		}
+	default:
+		if moved.data, err = ReadFile(oldPath); err != nil {
+			return err
+		}
+	}
+	plan.note(Op{Action: OpRename, Path: oldPath, Target: newPath}, &node{removed: true})
+	plan.nodes[filepath.Clean(newPath)] = moved
+	return nil
+}
+
+// Change makes a change outside the file system with apply, such as adding a
+// directory to PATH in the Windows registry. While planning, op is noted instead.
+func Change(op Op, apply func() error) error {
+	if plan == nil {
+		return apply()
+	}
+	plan.ops = append(plan.ops, op)
+	return nil
+}
+
+// Stat describes path, following symlinks
+func Stat(path string) (fs.FileInfo, error) {
+	if plan == nil {
+		return os.Stat(path)
+	}
+	for range 40 {
+		n, planned := plan.nodes[filepath.Clean(path)]
+		if !planned {
+			return os.Stat(path)
+		}
+		if n.removed {
+			return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+		}
+		if n.link == "" {
+			return n.info(path), nil
+		}
+		path = resolveLink(path, n.link)
+	}
+	return nil, &fs.PathError{Op: "stat", Path: path, Err: syscall.ELOOP}
+}
+
+// Lstat describes path without following a symlink
+func Lstat(path string) (fs.FileInfo, error) {
+	if plan == nil {
+		return os.Lstat(path)
+	}
+	n, planned := plan.nodes[filepath.Clean(path)]
+	switch {
+	case !planned:
+		return os.Lstat(path)
+	case n.removed:
+		return nil, &fs.PathError{Op: "lstat", Path: path, Err: fs.ErrNotExist}
+	}
+	return n.info(path), nil
+}
+
+// Readlink returns where the symlink at path points
+func Readlink(path string) (string, error) {
+	if plan == nil {
+		return os.Readlink(path)
+	}
+	n, planned := plan.nodes[filepath.Clean(path)]
+	switch {
+	case !planned:
+		return os.Readlink(path)
+	case n.removed:
+		return "", &fs.PathError{Op: "readlink", Path: path, Err: fs.ErrNotExist}
+	case n.link == "":
+		return "", &fs.PathError{Op: "readlink", Path: path, Err: syscall.EINVAL}
+	}
+	return n.link, nil
+}
+
+// ReadFile returns the content of path, following symlinks
+func ReadFile(path string) ([]byte, error) {
+	if plan == nil {
+		return os.ReadFile(path)
+	}
+	for range 40 {
+		n, planned := plan.nodes[filepath.Clean(path)]
+		switch {
+		case !planned:
+			return os.ReadFile(path)
+		case n.removed:
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		case n.dir:
+			return nil, &fs.PathError{Op: "read", Path: path, Err: syscall.EISDIR}
+		case n.link == "":
+			return bytes.Clone(n.data), nil
+		}
+		path = resolveLink(path, n.link)
+	}
+	return nil, &fs.PathError{Op: "open", Path: path, Err: syscall.ELOOP}
+}
+
+// ReadDir lists a directory sorted by name
+func ReadDir(dir string) ([]fs.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if plan == nil {
+		return entries, err
+	}
+	if err != nil {
+		if info, statErr := Stat(dir); statErr != nil || !info.IsDir() {
+			return nil, err
+		}
+	}
+
+	byName := make(map[string]fs.DirEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name()] = entry
+	}
+	clean := filepath.Clean(dir)
+	for path, n := range plan.nodes {
+		if filepath.Dir(path) != clean {
+			continue
+		}
+		name := filepath.Base(path)
+		if n.removed {
+			delete(byName, name)
+		} else {
+			byName[name] = fs.FileInfoToDirEntry(n.info(path))
+		}
+	}
+
+	merged := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
+
+// note adds op to the plan and makes n what is at its path
+func (r *recorder) note(op Op, n *node) {
+	r.ops = append(r.ops, op)
+	r.nodes[filepath.Clean(op.Path)] = n
+}
+
+// checkParent fails like the OS would when the directory path goes in is missing
+func (r *recorder) checkParent(op, path string) error {
+	if info, err := Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return nil
+}
+
+// resolveLink returns the path a symlink at path pointing at target leads to
+func resolveLink(path, target string) string {
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(filepath.Dir(path), target)
+}
+
+// info describes a planned node
+func (n *node) info(path string) fs.FileInfo {
+	return &fileInfo{name: filepath.Base(path), node: n}
+}
+
+// fileInfo is the fs.FileInfo of a planned node
+type fileInfo struct {
+	name string
+	node *node
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i *fileInfo) ModTime() time.Time { return time.Now() }
+func (i *fileInfo) IsDir() bool        { return i.node.dir }
+func (i *fileInfo) Sys() any           { return nil }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	switch {
+	case i.node.dir:
+		return fs.ModeDir | 0755
+	case i.node.link != "":
+		return fs.ModeSymlink | 0777
+	}
+	return i.node.mode
+}
@@ -0,0 +1,155 @@
+package fsops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	if err := os.WriteFile(existing, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(dir, "bin")
+	script := filepath.Join(bin, "script")
+	link := filepath.Join(bin, "link")
+	log := filepath.Join(dir, "log")
+
+	ops, err := Record(func() error {
+		if !Planning() {
+			t.Error("Planning() is false inside Record")
+		}
+		if err := WriteFile(script, []byte("x"), 0755); err == nil {
+			t.Error("WriteFile into a missing directory should fail")
+		}
+		if err := MkdirAll(bin, 0755); err != nil {
+			return err
+		}
+		if err := WriteAtomic(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+			return err
+		}
+		if err := Symlink(existing, link); err != nil {
+			return err
+		}
+		if err := Symlink(existing, link); !os.IsExist(err) {
+			t.Errorf("a second Symlink returned %v, want an exists error", err)
+		}
+		if err := WriteFile(existing, []byte("new\n"), 0644); err != nil {
+			return err
+		}
+		if err := AppendFile(log, []byte("one\n"), 0644); err != nil {
+			return err
+		}
+		if err := AppendFile(log, []byte("two\n"), 0644); err != nil {
+			return err
+		}
+
+		// Reads see what was planned
+		if info, err := Stat(script); err != nil || info.Mode().Perm() != 0755 {
+			t.Errorf("Stat(script) = %v, %v", info, err)
+		}
+		if data, err := ReadFile(link); err != nil || string(data) != "new\n" {
+			t.Errorf("ReadFile through the planned link = %q, %v", data, err)
+		}
+		if target, err := Readlink(link); err != nil || target != existing {
+			t.Errorf("Readlink = %q, %v", target, err)
+		}
+		if data, err := ReadFile(log); err != nil || string(data) != "one\ntwo\n" {
+			t.Errorf("ReadFile(log) = %q, %v", data, err)
+		}
+		if entries, err := ReadDir(bin); err != nil || len(entries) != 2 {
+			t.Errorf("ReadDir(bin) = %v, %v", entries, err)
+		}
+
+		moved := filepath.Join(bin, "moved")
+		if err := Rename(script, moved); err != nil {
+			return err
+		}
+		if _, err := Lstat(script); !os.IsNotExist(err) {
+			t.Errorf("Lstat of a renamed file returned %v", err)
+		}
+		if data, err := ReadFile(moved); err != nil || string(data) != "#!/bin/sh\n" {
+			t.Errorf("ReadFile(moved) = %q, %v", data, err)
+		}
+		if err := Rename(script, moved); err == nil {
+			t.Error("renaming a missing file should fail")
+		}
+
+		if err := Remove(existing); err != nil {
+			return err
+		}
+		if _, err := Stat(existing); !os.IsNotExist(err) {
+			t.Errorf("Stat after Remove returned %v", err)
+		}
+		if _, err := Stat(link); !os.IsNotExist(err) {
+			t.Errorf("a link to a removed file should dangle, got %v", err)
+		}
+		if err := Remove(existing); !os.IsNotExist(err) {
+			t.Errorf("a second Remove returned %v", err)
+		}
+		return Change(Op{Action: OpAddPath, Path: bin}, func() error {
+			t.Error("Change applied its change while planning")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if Planning() {
+		t.Error("Planning() is true after Record")
+	}
+
+	var got []string
+	for _, op := range ops {
+		got = append(got, string(op.Action)+" "+filepath.Base(op.Path))
+	}
+	want := "mkdir bin, write script, symlink link, write existing, append log, append log, rename script, remove existing, add-path bin"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("ops = %s\nwant %s", strings.Join(got, ", "), want)
+	}
+	if ops[1].Before != nil || ops[3].Before == nil || *ops[3].Before != "old\n" {
+		t.Errorf("Before of a new and a replaced file = %v, %v", ops[1].Before, ops[3].Before)
+	}
+	if ops[2].Target != existing || ops[5].Content != "two\n" || ops[6].Target != filepath.Join(bin, "moved") {
+		t.Errorf("ops[2] = %+v, ops[5] = %+v, ops[6] = %+v", ops[2], ops[5], ops[6])
+	}
+
+	// Nothing was changed
+	if _, err := os.Stat(bin); !os.IsNotExist(err) {
+		t.Errorf("the bin directory was created: %v", err)
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "old\n" {
+		t.Errorf("existing holds %q, %v", data, err)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Errorf("the log was created: %v", err)
+	}
+}
+
+func TestWithoutPlan(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "file")
+	if err := MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteAtomic(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendFile(path, []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ReadFile(path); err != nil || string(data) != "ab" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("WriteAtomic left its temporary file: %v", err)
+	}
+	applied := false
+	Change(Op{Action: OpAddPath}, func() error { applied = true; return nil })
+	if !applied {
+		t.Error("Change did not apply its change")
+	}
+}
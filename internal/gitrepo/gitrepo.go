@@ -0,0 +1,168 @@
+// Package gitrepo runs the few git commands lnb sync needs on a clone it
+// owns. Everything goes through the git executable on PATH.
+package gitrepo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// syncedRef remembers the commit lnb last synced with
+const syncedRef = "refs/lnb/synced"
+
+// ErrNotCloned is returned by Open when dir is not a git work tree
+var ErrNotCloned = errors.New("not a git repository")
+
+// Repo is a clone in Dir
+type Repo struct {
+	Dir string
+}
+
+// Clone clones remote into dir, which must not exist yet. An empty remote is
+// fine; the first push creates its default branch.
+func Clone(remote, dir string) (*Repo, error) {
+	if _, err := run("", "clone", "--quiet", remote, dir); err != nil {
+		return nil, err
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// Open returns the clone in dir
+func Open(dir string) (*Repo, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotCloned, dir)
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// Remote returns the URL of origin
+func (r *Repo) Remote() (string, error) {
+	return r.git("remote", "get-url", "origin")
+}
+
+// Branch returns the branch checked out
+func (r *Repo) Branch() (string, error) {
+	return r.git("symbolic-ref", "--short", "HEAD")
+}
+
+// Fetch updates the remote-tracking branches from origin
+func (r *Repo) Fetch() error {
+	_, err := r.git("fetch", "--quiet", "origin")
+	return err
+}
+
+// Upstream returns the remote-tracking ref of the branch checked out, and
+// whether origin has that branch yet
+func (r *Repo) Upstream() (string, bool, error) {
+	branch, err := r.Branch()
+	if err != nil {
+		return "", false, err
+	}
+	ref := "refs/remotes/origin/" + branch
+	if _, err := r.git("rev-parse", "--verify", "--quiet", ref); err != nil {
+		return ref, false, nil
+	}
+	return ref, true, nil
+}
+
+// Synced returns the commit recorded by MarkSynced, and whether there is one
+func (r *Repo) Synced() (string, bool) {
+	commit, err := r.git("rev-parse", "--verify", "--quiet", syncedRef)
+	return commit, err == nil
+}
+
+// MarkSynced records ref as the commit last synced with
+func (r *Repo) MarkSynced(ref string) error {
+	_, err := r.git("update-ref", syncedRef, ref)
+	return err
+}
+
+// Reset makes the branch and work tree match ref, dropping anything else
+func (r *Repo) Reset(ref string) error {
+	if _, err := r.git("reset", "--quiet", "--hard", ref); err != nil {
+		return err
+	}
+	_, err := r.git("clean", "--quiet", "-fd")
+	return err
+}
+
+// ReadTree returns the contents of the files under dir at ref, keyed by their
+// path relative to dir
+func (r *Repo) ReadTree(ref, dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	list, err := r.git("ls-tree", "-r", "--name-only", ref, "--", dir+"/")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(list, "\n") {
+		if path == "" {
+			continue
+		}
+		cmd := exec.Command("git", "show", ref+":"+path)
+		cmd.Dir = r.Dir
+		data, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git show %s:%s: %v", ref, path, err)
+		}
+		files[strings.TrimPrefix(path, dir+"/")] = data
+	}
+	return files, nil
+}
+
+// Commit records every change in the work tree with message. It reports
+// whether there was anything to commit.
+func (r *Repo) Commit(message string) (bool, error) {
+	if _, err := r.git("add", "--all"); err != nil {
+		return false, err
+	}
+	if _, err := r.git("diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+
+	args := []string{"commit", "--quiet", "-m", message}
+	if email, _ := r.git("config", "user.email"); email == "" {
+		// git refuses to commit without an identity; lnb's will do
+		args = append([]string{"-c", "user.name=lnb", "-c", "user.email=lnb@localhost"}, args...)
+	}
+	if _, err := r.git(args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Push sends the branch checked out to origin
+func (r *Repo) Push() error {
+	branch, err := r.Branch()
+	if err != nil {
+		return err
+	}
+	_, err = r.git("push", "--quiet", "origin", "HEAD:refs/heads/"+branch)
+	return err
+}
+
+// git runs a git command in the clone
+func (r *Repo) git(args ...string) (string, error) {
+	return run(r.Dir, args...)
+}
+
+// run runs git in dir and returns its trimmed output. Errors carry what git
+// printed to stderr.
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
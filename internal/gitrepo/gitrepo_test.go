@@ -0,0 +1,75 @@
+package gitrepo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneCommitPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	if _, err := run("", "init", "--quiet", "--bare", remote); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+
+	first, err := Clone(remote, filepath.Join(dir, "first"))
+	if err != nil {
+		t.Fatalf("Clone of an empty remote: %v", err)
+	}
+	if branch, err := first.Branch(); err != nil || branch == "" {
+		t.Errorf("Branch() = %q, %v", branch, err)
+	}
+	if _, exists, err := first.Upstream(); err != nil || exists {
+		t.Errorf("an empty remote should have no upstream, got %v, %v", exists, err)
+	}
+	if _, synced := first.Synced(); synced {
+		t.Error("a fresh clone should not be marked as synced")
+	}
+
+	if err := os.MkdirAll(filepath.Join(first.Dir, "entries"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(first.Dir, "entries", "deploy.json"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if committed, err := first.Commit("add deploy"); err != nil || !committed {
+		t.Fatalf("Commit() = %v, %v", committed, err)
+	}
+	if committed, err := first.Commit("nothing"); err != nil || committed {
+		t.Errorf("Commit() with no changes = %v, %v", committed, err)
+	}
+	if err := first.Push(); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if err := first.MarkSynced("HEAD"); err != nil {
+		t.Fatalf("MarkSynced: %v", err)
+	}
+	if _, synced := first.Synced(); !synced {
+		t.Error("MarkSynced did not mark the clone")
+	}
+
+	second, err := Clone(remote, filepath.Join(dir, "second"))
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if err := second.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	ref, exists, err := second.Upstream()
+	if err != nil || !exists {
+		t.Fatalf("Upstream() = %q, %v, %v", ref, exists, err)
+	}
+	files, err := second.ReadTree(ref, "entries")
+	if err != nil || string(files["deploy.json"]) != "{}\n" || len(files) != 1 {
+		t.Errorf("ReadTree() = %q, %v", files, err)
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Open should fail where there is no clone")
+	}
+}
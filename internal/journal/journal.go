@@ -0,0 +1,135 @@
+// Package journal keeps the append-only record of what lnb changed, one JSON
+// object per line in journal.jsonl next to the config file. Each record holds
+// the entries an operation touched as they were before and after it, and the
+// files it replaced or removed, so that the operation can be reversed.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+)
+
+// fileName is the journal's name in the config directory
+const fileName = "journal.jsonl"
+
+// Record is one operation
+type Record struct {
+	ID      int       `json:"id"`
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`                // e.g. install, alias, remove, edit, move, undo
+	Profile string    `json:"profile,omitempty"` // the profile in use; empty means the default
+	Undoes  int       `json:"undoes,omitempty"`  // the record an undo reversed
+	Changes []*Change `json:"changes"`
+}
+
+// Change is what an operation did to one entry
+type Change struct {
+	Name   string           `json:"name"`
+	Before *config.LnbEntry `json:"before,omitempty"` // nil if the entry did not exist
+	After  *config.LnbEntry `json:"after,omitempty"`  // nil if the operation removed it
+	Files  []File           `json:"files,omitempty"`  // the entry's files as they were before
+}
+
+// File is a generated file as it was before an operation. Symlinks and
+// binary files are not kept; lnb recreates them from the entry.
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Names returns the names of the entries the record touched
+func (r *Record) Names() []string {
+	names := make([]string, len(r.Changes))
+	for i, change := range r.Changes {
+		names[i] = change.Name
+	}
+	return names
+}
+
+// Path returns where the journal is kept
+func Path() (string, error) {
+	return config.DataPath(fileName)
+}
+
+// Load reads every record, oldest first. A missing journal is empty.
+func Load() ([]*Record, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := fsops.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		records = append(records, &record)
+	}
+	return records, scanner.Err()
+}
+
+// Append numbers record after the last one, stamps it and adds it to the journal
+func Append(record *Record) error {
+	records, err := Load()
+	if err != nil {
+		return err
+	}
+	record.ID = 1
+	if len(records) > 0 {
+		record.ID = records[len(records)-1].ID + 1
+	}
+	record.Time = time.Now()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return fsops.AppendFile(path, append(data, '\n'), 0644)
+}
+
+// Find returns the record with the given id
+func Find(records []*Record, id int) (*Record, bool) {
+	for _, record := range records {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return nil, false
+}
+
+// UndoneBy returns the undo record that reversed the record with the given
+// id, if there is one
+func UndoneBy(records []*Record, id int) (*Record, bool) {
+	for _, record := range records {
+		if record.Undoes == id {
+			return record, true
+		}
+	}
+	return nil, false
+}
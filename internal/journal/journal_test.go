@@ -0,0 +1,60 @@
+package journal
+
+import (
+	"reflect"
+	"testing"
+
+	"lnb/internal/config"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv("LNB_TEST_CONFIG_DIR", t.TempDir())
+
+	if records, err := Load(); err != nil || len(records) != 0 {
+		t.Fatalf("Load() of a missing journal = %v, %v", records, err)
+	}
+
+	entry := &config.LnbEntry{Name: "deploy", Kind: config.KindAlias, Command: config.ParseCommand("echo deploy")}
+	first := &Record{Op: "alias", Changes: []*Change{{Name: "deploy", After: entry}}}
+	second := &Record{Op: "remove", Changes: []*Change{{
+		Name:   "deploy",
+		Before: entry,
+		Files:  []File{{Path: "/bin/deploy", Content: "#!/bin/bash\necho deploy \"$@\"\n"}},
+	}}}
+	undo := &Record{Op: "undo", Undoes: 2, Changes: []*Change{{Name: "deploy", After: entry}}}
+	for _, record := range []*Record{first, second, undo} {
+		if err := Append(record); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	records, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Load() returned %d records, want 3", len(records))
+	}
+	for i, record := range records {
+		if record.ID != i+1 || record.Time.IsZero() {
+			t.Errorf("record %d has id %d and time %v", i, record.ID, record.Time)
+		}
+	}
+	if got := records[1].Changes[0]; got.After != nil || !reflect.DeepEqual(got.Files, second.Changes[0].Files) ||
+		got.Before.Command.String() != "echo deploy" {
+		t.Errorf("the remove record did not survive a round trip: %+v", got)
+	}
+	if got := records[0].Names(); !reflect.DeepEqual(got, []string{"deploy"}) {
+		t.Errorf("Names() = %v", got)
+	}
+
+	if record, found := Find(records, 2); !found || record.Op != "remove" {
+		t.Errorf("Find(2) = %v, %v", record, found)
+	}
+	if undoneBy, undone := UndoneBy(records, 2); !undone || undoneBy.ID != 3 {
+		t.Errorf("UndoneBy(2) = %v, %v", undoneBy, undone)
+	}
+	if _, undone := UndoneBy(records, 1); undone {
+		t.Error("UndoneBy(1) found an undo")
+	}
+}
@@ -0,0 +1,107 @@
+// Package names validates the names lnb creates commands under. Every alias,
+// binary link and wrapper name goes through Validate before anything is
+// written, so a name can never point outside the bin directory.
+package names
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the longest name lnb accepts
+const MaxLength = 64
+
+// ErrInvalid is matched by every error returned from this package
+var ErrInvalid = errors.New("invalid name")
+
+// Error explains which rule a name broke
+type Error struct {
+	Name   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid name '%s': %s", e.Name, e.Reason)
+}
+
+func (e *Error) Unwrap() error { return ErrInvalid }
+
+// windowsReserved are device names Windows refuses as file names, with or without an extension
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM0": true, "COM1": true, "COM2": true, "COM3": true, "COM4": true,
+	"COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT0": true, "LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true,
+	"LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Validate checks that name is usable as a command name on goos
+func Validate(name, goos string) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &Error{Name: name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if name == "" {
+		return invalid("name cannot be empty")
+	}
+	if len(name) > MaxLength {
+		return invalid("name is %d characters long, the maximum is %d", len(name), MaxLength)
+	}
+	if name == "." || name == ".." {
+		return invalid("'.' and '..' refer to directories")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return invalid("name must not contain path separators")
+	}
+	if strings.HasPrefix(name, "-") {
+		return invalid("name must not start with '-', it would be read as an option")
+	}
+	if strings.HasPrefix(name, ".") {
+		return invalid("name must not start with '.', it would be a hidden file")
+	}
+
+	for _, char := range name {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || strings.ContainsRune("._-+", char) {
+			continue
+		}
+		if unicode.IsSpace(char) {
+			return invalid("name must not contain whitespace")
+		}
+		return invalid("character %q is not allowed (use letters, digits, '.', '_', '-' or '+')", char)
+	}
+
+	if goos == "windows" {
+		if strings.HasSuffix(name, ".") {
+			return invalid("Windows does not allow names ending in '.'")
+		}
+		base, _, _ := strings.Cut(name, ".")
+		if windowsReserved[strings.ToUpper(base)] {
+			return invalid("'%s' is a reserved device name on Windows", base)
+		}
+	}
+
+	return nil
+}
+
+// CaseInsensitive reports whether file names on goos that differ only in case refer to the same file
+func CaseInsensitive(goos string) bool {
+	return goos == "windows" || goos == "darwin"
+}
+
+// CheckCollision returns an error if name differs only in case from one of
+// existing on a case-insensitive platform. Exact matches are left to the
+// caller, which reports them as already installed.
+func CheckCollision(name string, existing []string, goos string) error {
+	if !CaseInsensitive(goos) {
+		return nil
+	}
+
+	for _, other := range existing {
+		if other != name && strings.EqualFold(other, name) {
+			return &Error{Name: name, Reason: fmt.Sprintf("it differs only in case from existing entry '%s', and names are case-insensitive on %s", other, goos)}
+		}
+	}
+	return nil
+}
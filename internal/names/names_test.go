@@ -0,0 +1,100 @@
+package names
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var goosList = []string{"linux", "darwin", "windows"}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		invalid []string // the goos the name is refused on
+	}{
+		{"rg", nil},
+		{"node18", nil},
+		{"my-tool_2.0+beta", nil},
+		{"café", nil},
+		{strings.Repeat("a", MaxLength), nil},
+		{strings.Repeat("a", MaxLength+1), goosList},
+		{"", goosList},
+		{".", goosList},
+		{"..", goosList},
+		{"../evil", goosList},
+		{`..\evil`, goosList},
+		{"-rf", goosList},
+		{".hidden", goosList},
+		{"two words", goosList},
+		{"tab\there", goosList},
+		{"semi;colon", goosList},
+		{"dollar$", goosList},
+		{"CON", []string{"windows"}},
+		{"con", []string{"windows"}},
+		{"NUL", []string{"windows"}},
+		{"nul.txt", []string{"windows"}},
+		{"PRN", []string{"windows"}},
+		{"AUX", []string{"windows"}},
+		{"COM1", []string{"windows"}},
+		{"com9.exe", []string{"windows"}},
+		{"LPT1", []string{"windows"}},
+		{"COM10", nil},
+		{"CONSOLE", nil},
+		{"trailing.", []string{"windows"}},
+	}
+
+	for _, tc := range tests {
+		for _, goos := range goosList {
+			wantErr := false
+			for _, refused := range tc.invalid {
+				wantErr = wantErr || refused == goos
+			}
+			err := Validate(tc.name, goos)
+			if (err != nil) != wantErr {
+				t.Errorf("Validate(%q, %s) = %v, want error: %v", tc.name, goos, err, wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("Validate(%q, %s) = %v, which does not match ErrInvalid", tc.name, goos, err)
+			}
+		}
+	}
+}
+
+func TestCheckCollision(t *testing.T) {
+	existing := []string{"Tool", "rg"}
+	tests := []struct {
+		name       string
+		goos       string
+		wantErr    bool
+		wantReason string
+	}{
+		{"tool", "linux", false, ""},
+		{"tool", "darwin", true, "existing entry 'Tool'"},
+		{"TOOL", "windows", true, "existing entry 'Tool'"},
+		{"RG", "windows", true, "existing entry 'rg'"},
+		{"Tool", "darwin", false, ""}, // exact matches are left to the caller
+		{"rg", "windows", false, ""},
+		{"tool2", "windows", false, ""},
+	}
+
+	for _, tc := range tests {
+		err := CheckCollision(tc.name, existing, tc.goos)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("CheckCollision(%q, %s) = %v, want error: %v", tc.name, tc.goos, err, tc.wantErr)
+			continue
+		}
+		if err != nil && (!errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tc.wantReason)) {
+			t.Errorf("CheckCollision(%q, %s) = %v, want an ErrInvalid mentioning %q", tc.name, tc.goos, err, tc.wantReason)
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	want := map[string]bool{"linux": false, "darwin": true, "windows": true, "freebsd": false}
+	for goos, insensitive := range want {
+		if got := CaseInsensitive(goos); got != insensitive {
+			t.Errorf("CaseInsensitive(%s) = %v, want %v", goos, got, insensitive)
+		}
+	}
+}
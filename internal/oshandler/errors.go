@@ -0,0 +1,52 @@
+package oshandler
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"lnb/internal/config"
+	"lnb/internal/names"
+)
+
+// Sentinel errors returned (wrapped) by handlers so callers can use errors.Is
+var (
+	ErrNotExist         = errors.New("file does not exist")
+	ErrNotExecutable    = errors.New("file is not executable")
+	ErrAlreadyInstalled = errors.New("already installed")
+	ErrTargetExists     = errors.New("target already exists")
+	ErrNotInstalled     = errors.New("not installed by lnb")
+	ErrTargetMismatch   = errors.New("target path mismatch")
+	ErrInvalidCommand   = errors.New("invalid command")
+	ErrInvalidName      = names.ErrInvalid
+)
+
+// kindError keeps the user-facing message while exposing a sentinel via Unwrap.
+// The lnb package reports its own sentinels with it too.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// Errorf formats a user-facing error that matches kind with errors.Is
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+// checkNewName validates a name for a new entry, including case-insensitive
+// collisions with entries already in the config
+func checkNewName(cfg *config.Config, name string) error {
+	if err := names.Validate(name, runtime.GOOS); err != nil {
+		return err
+	}
+
+	existing := make([]string, 0, len(cfg.Entries))
+	for other := range cfg.Entries {
+		existing = append(existing, other)
+	}
+	return names.CheckCollision(name, existing, runtime.GOOS)
+}
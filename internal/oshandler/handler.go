@@ -1,11 +1,169 @@
 package oshandler
 
-import "runtime"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+)
+
+// Result describes what a handler did for a single install or remove
+type Result struct {
+	Name       string   // entry name as recorded in the config
+	SourcePath string   // binary source path, empty for aliases
+	Command    string   // alias command as written to the wrapper, empty for binaries
+	TargetPath string   // symlink or wrapper location
+	Warnings   []string // non-fatal problems encountered along the way
+	Notes      []string // informational messages, e.g. PATH changes
+}
+
+func (r *Result) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) notef(format string, args ...interface{}) {
+	r.Notes = append(r.Notes, fmt.Sprintf(format, args...))
+}
+
+// Options tune how an entry is created. The zero value gives each OS's
+// default behaviour, and options that do not apply to an OS are ignored.
+type Options struct {
+	Shim    config.ShimStyle // Windows: which launchers to write, default cmd
+	Posix   bool             // Windows: also write an extensionless sh launcher
+	Desktop bool             // Linux: also write a .desktop menu entry
+	Literal bool             // aliases: the command is used exactly as given
+
+	Description string   // free text shown by list and info
+	Tags        []string // normalized with config.NormalizeTags
+
+	// Container marks an alias as a container alias; its command must be
+	// the one Container.Command renders
+	Container *config.Container
+
+	// Local marks an alias as a local alias, whose command runs the nearest
+	// project's definition; Fallback is what runs where there is none
+	Local    bool
+	Fallback *config.Command
+
+	// Variants are commands an alias runs instead of its own on particular
+	// operating systems, keyed by GOOS
+	Variants map[string]*config.Command
+}
+
+// EntryOptions returns the options an existing entry was created with
+func EntryOptions(entry *config.LnbEntry) Options {
+	return Options{
+		Shim:      entry.Shim,
+		Posix:     entry.PosixShim,
+		Desktop:   entry.Desktop,
+		Literal:   entry.Literal,
+		Container: entry.Container,
+		Local:     entry.Kind == config.KindLocal,
+		Fallback:  entry.Fallback,
+		Variants:  entry.Variants,
+
+		Description: entry.Description,
+		Tags:        entry.Tags,
+	}
+}
+
+// File is a file lnb generates for an entry: a script with Content, or a
+// symlink pointing at Link
+type File struct {
+	Path    string
+	Content string
+	Link    string
+}
 
 // Handler interface defines methods for OS-specific operations
 type Handler interface {
-	Handle(absPath, action string) error
-	HandleAlias(aliasName, command, action string) error
+	Handle(absPath, name, action string, opts Options) (*Result, error)
+	HandleAlias(aliasName string, command *config.Command, action string, opts Options) (*Result, error)
+	BinDir() string
+	LinkName(absPath string) string
+
+	// Files renders the launchers lnb would write for entry today, without
+	// touching the disk. Files it cannot regenerate, such as icons and menu
+	// entries, are left out.
+	Files(entry *config.LnbEntry) []File
+}
+
+// binDirOr returns the bin directory set by LNB_BIN_DIR or the config for the
+// profile in use, or def
+func binDirOr(def string) string {
+	if dir := os.Getenv("LNB_BIN_DIR"); dir != "" {
+		return dir
+	}
+	if cfg, err := config.Load(); err == nil && cfg.ActiveBinDir() != "" {
+		return cfg.ActiveBinDir()
+	}
+	return def
+}
+
+// entryTarget returns where a file called fileName belonging to entry lives.
+// Entries stay in the bin dir they were created in, which is not necessarily
+// the current one.
+func entryTarget(entry *config.LnbEntry, fileName string) string {
+	return filepath.Join(filepath.Dir(entry.TargetPath), fileName)
+}
+
+// recordEntry stores the description and tags from the options on a new entry
+func recordEntry(entry *config.LnbEntry, opts Options) {
+	entry.Description = opts.Description
+	entry.Tags = opts.Tags
+}
+
+// recordAlias stores what the options say about an alias on its entry
+func recordAlias(entry *config.LnbEntry, opts Options) {
+	recordEntry(entry, opts)
+	entry.Literal = opts.Literal
+	entry.Variants = opts.Variants
+	if opts.Container != nil {
+		entry.Kind = config.KindContainer
+		entry.Container = opts.Container
+	}
+	if opts.Local {
+		entry.Kind = config.KindLocal
+		entry.Fallback = opts.Fallback
+	}
+}
+
+// aliasCommand returns the command an alias entry's wrapper runs. A container
+// alias is rendered again from its spec.
+func aliasCommand(entry *config.LnbEntry) *config.Command {
+	if entry.Container != nil {
+		return entry.Container.Command(runtime.GOOS)
+	}
+	return entry.CommandFor(runtime.GOOS)
+}
+
+// commandFor returns the command an alias being created runs on goos: its
+// variant for goos if the options have one, else command
+func commandFor(command *config.Command, opts Options, goos string) *config.Command {
+	if variant, ok := opts.Variants[goos]; ok {
+		return variant
+	}
+	return command
+}
+
+// removeTargets deletes an entry's target and any extra files generated with
+// it. A missing file is not an error; the user may have deleted it, or the
+// entry may never have been created again after its profile was switched to.
+func removeTargets(entry *config.LnbEntry, result *Result) error {
+	if err := fsops.Remove(entry.TargetPath); os.IsNotExist(err) {
+		result.warnf("%s was already gone", entry.TargetPath)
+	} else if err != nil {
+		return err
+	}
+	for _, extra := range entry.ExtraTargets {
+		if err := fsops.Remove(extra); err != nil && !os.IsNotExist(err) {
+			result.warnf("failed to remove %s: %v", extra, err)
+		}
+	}
+	return nil
 }
 
 // New returns the appropriate handler based on OS
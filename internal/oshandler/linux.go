@@ -4,265 +4,273 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"lnb/internal/config"
+	"lnb/internal/desktop"
+	"lnb/internal/fsops"
+	"lnb/internal/wsl"
 )
 
-// parseShellArgs parses a command string into arguments while respecting quotes
-func parseShellArgsLinux(command string) []string {
-	var args []string
-	var current strings.Builder
-	var inQuotes bool
-	var quoteChar rune
-
-	for _, char := range command {
-		switch char {
-		case '"', '\'':
-			if !inQuotes {
-				inQuotes = true
-				quoteChar = char
-				current.WriteRune(char) // Keep the quote in the argument
-			} else if char == quoteChar {
-				inQuotes = false
-				current.WriteRune(char) // Keep the closing quote
-				quoteChar = 0
-			} else {
-				current.WriteRune(char)
-			}
-		case ' ', '\t':
-			if inQuotes {
-				current.WriteRune(char)
-			} else {
-				if current.Len() > 0 {
-					args = append(args, current.String())
-					current.Reset()
-				}
-			}
-		default:
-			current.WriteRune(char)
-		}
-	}
-
-	if current.Len() > 0 {
-		args = append(args, current.String())
-	}
+type linuxHandler struct{}
 
-	return args
+// BinDir returns the directory symlinks and alias scripts are written to
+func (h *linuxHandler) BinDir() string {
+	return binDirOr("/usr/local/bin")
 }
 
-// reconstructCommandLinux rebuilds a command string from parsed arguments
-func reconstructCommandLinux(args []string) string {
-	return strings.Join(args, " ")
+// LinkName returns the name a binary is installed under by default. Inside
+// WSL a Windows program loses its extension, as it would on Windows.
+func (h *linuxHandler) LinkName(absPath string) string {
+	if h.isWindowsTarget(absPath) {
+		return wsl.LinkName(absPath)
+	}
+	return filepath.Base(absPath)
 }
 
-type linuxHandler struct{}
+// isWindowsTarget reports whether absPath is a Windows program run through WSL interop
+func (h *linuxHandler) isWindowsTarget(absPath string) bool {
+	return wsl.IsWindowsTarget(absPath) && wsl.Detect()
+}
 
-func (h *linuxHandler) Handle(absPath, action string) error {
-	linkName := filepath.Base(absPath)
-	linkPath := "/usr/local/bin/" + linkName
+// Handle installs or removes a binary. The link is called name, or the
+// binary's base name when name is empty.
+func (h *linuxHandler) Handle(absPath, name, action string, opts Options) (*Result, error) {
+	linkName := name
+	if linkName == "" {
+		linkName = h.LinkName(absPath)
+	}
+	linkPath := filepath.Join(h.BinDir(), linkName)
+	result := &Result{Name: linkName, SourcePath: absPath, TargetPath: linkPath}
 
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
 	switch action {
 	case "install":
 		// Check if file exists
-		if _, err := os.Stat(absPath); os.IsNotExist(err) {
-			return fmt.Errorf("file '%s' does not exist", absPath)
+		if _, err := fsops.Stat(absPath); os.IsNotExist(err) {
+			return nil, Errorf(ErrNotExist, "file '%s' does not exist", absPath)
 		}
 
 		// Check if file is executable
 		if err := h.checkExecutable(absPath); err != nil {
-			return fmt.Errorf("file '%s' is not executable: %v", absPath, err)
+			return nil, Errorf(ErrNotExecutable, "file '%s' is not executable: %v", absPath, err)
+		}
+
+		if err := checkNewName(cfg, linkName); err != nil {
+			return nil, err
 		}
 
 		// Check if this binary is already installed
 		if entry, exists := cfg.GetEntry(linkName); exists {
 			// Verify the target file actually exists
-			if _, err := os.Stat(entry.TargetPath); err == nil {
-				return fmt.Errorf("binary '%s' is already installed. Use 'lnb remove %s' first to reinstall", linkName, linkName)
+			if _, err := fsops.Stat(entry.TargetPath); err == nil {
+				return nil, Errorf(ErrAlreadyInstalled, "binary '%s' is already installed. Use 'lnb remove %s' first to reinstall", linkName, linkName)
 			} else {
 				// Config says it's installed but file doesn't exist - clean up the config
-				fmt.Printf("Warning: Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.\n", linkName, entry.TargetPath)
+				result.warnf("Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.", linkName, entry.TargetPath)
 				cfg.RemoveEntry(linkName)
 				if err := cfg.Save(); err != nil {
-					fmt.Printf("Warning: failed to clean up config: %v\n", err)
+					result.warnf("failed to clean up config: %v", err)
 				}
 			}
 		}
 
 		// Check if the target path already exists
-		if _, err := os.Stat(linkPath); err == nil {
-			return fmt.Errorf("file already exists at %s. Please remove it manually or use 'lnb remove %s' if it was installed by LNB", linkPath, linkName)
+		if _, err := fsops.Stat(linkPath); err == nil {
+			return nil, Errorf(ErrTargetExists, "file already exists at %s. Please remove it manually or use 'lnb remove %s' if it was installed by LNB", linkPath, linkName)
+		}
+
+		if err := fsops.MkdirAll(h.BinDir(), 0755); err != nil {
+			return nil, fmt.Errorf("error creating bin dir: %v", err)
+		}
+
+		// A Windows program gets a wrapper that converts path arguments
+		if h.isWindowsTarget(absPath) {
+			wrapper := wsl.Wrapper(absPath, os.Getenv("WSL_DISTRO_NAME"))
+			if err := fsops.WriteFile(linkPath, []byte(wrapper), 0755); err != nil {
+				return nil, fmt.Errorf("failed to install: %v", err)
+			}
+			result.notef("'%s' is a Windows program; path arguments are converted to Windows form when it runs", linkName)
+
+			entry := cfg.AddBinary(linkName, absPath, linkPath, config.ModeWrapper)
+			entry.Kind = config.KindShim
+			recordEntry(entry, opts)
+			if err := cfg.Save(); err != nil {
+				result.warnf("failed to update config: %v", err)
+			}
+			return result, nil
 		}
 
-		err := os.Symlink(absPath, linkPath)
+		err := fsops.Symlink(absPath, linkPath)
 		if err != nil {
-			return fmt.Errorf("failed to install: %v", err)
+			return nil, fmt.Errorf("failed to install: %v", err)
+		}
+
+		// Add a menu entry for GUI programs
+		var desktopFiles []string
+		if opts.Desktop {
+			files, warnings, err := desktop.Install(linkName, linkPath, absPath)
+			if err != nil {
+				fsops.Remove(linkPath)
+				return nil, fmt.Errorf("failed to create desktop entry: %v", err)
+			}
+			result.Warnings = append(result.Warnings, warnings...)
+			result.notef("Added '%s' to the application menu: %s", linkName, files[0])
+			desktopFiles = files
 		}
-		fmt.Printf("Installed: %s -> %s\n", linkPath, absPath)
 
 		// Add to config
-		cfg.AddEntry(linkName, absPath, linkPath)
+		entry := cfg.AddBinary(linkName, absPath, linkPath, config.ModeSymlink)
+		recordEntry(entry, opts)
+		entry.Desktop = opts.Desktop
+		entry.ExtraTargets = desktopFiles
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 
 	case "remove":
 		// Check if this binary was installed by LNB
 		entry, exists := cfg.GetEntry(linkName)
 		if !exists {
-			return fmt.Errorf("binary '%s' was not installed by LNB", linkName)
+			return nil, Errorf(ErrNotInstalled, "binary '%s' was not installed by LNB", linkName)
 		}
 
 		// Verify the target path matches what we expect
+		linkPath = entryTarget(entry, linkName)
+		result.TargetPath = linkPath
 		if entry.TargetPath != linkPath {
-			return fmt.Errorf("binary '%s' target path mismatch: expected %s, found %s", linkName, linkPath, entry.TargetPath)
+			return nil, Errorf(ErrTargetMismatch, "binary '%s' target path mismatch: expected %s, found %s", linkName, linkPath, entry.TargetPath)
 		}
 
-		err := os.Remove(linkPath)
-		if err != nil {
-			return fmt.Errorf("failed to remove: %v", err)
+		if err := removeTargets(entry, result); err != nil {
+			return nil, fmt.Errorf("failed to remove: %v", err)
+		}
+		if entry.Desktop {
+			if desktopPath, err := desktop.Path(linkName); err == nil {
+				desktop.Removed(desktopPath)
+			}
 		}
-		fmt.Printf("Removed: %s\n", linkPath)
 
 		// Remove from config
 		cfg.RemoveEntry(linkName)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 	}
-	return nil
+	return result, nil
 }
 
-func (h *linuxHandler) HandleAlias(aliasName, command, action string) error {
-	scriptPath := "/usr/local/bin/" + aliasName
+func (h *linuxHandler) HandleAlias(aliasName string, command *config.Command, action string, opts Options) (*Result, error) {
+	scriptPath := filepath.Join(h.BinDir(), aliasName)
+	result := &Result{Name: aliasName, TargetPath: scriptPath}
 
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
 	switch action {
 	case "install":
+		// An alias with a variant for this OS runs that instead
+		run := commandFor(command, opts, "linux")
+
 		// Validate the command
-		if err := h.validateCommand(command); err != nil {
-			return fmt.Errorf("invalid command '%s': %v", command, err)
+		if err := validateCommand(run); err != nil {
+			return nil, Errorf(ErrInvalidCommand, "invalid command '%s': %v", run, err)
+		}
+
+		if err := checkNewName(cfg, aliasName); err != nil {
+			return nil, err
 		}
 
 		// Check if this alias is already installed
 		if entry, exists := cfg.GetEntry(aliasName); exists {
 			// Verify the target file actually exists
-			if _, err := os.Stat(entry.TargetPath); err == nil {
-				return fmt.Errorf("alias '%s' is already installed. Use 'lnb unalias %s' first to reinstall", aliasName, aliasName)
+			if _, err := fsops.Stat(entry.TargetPath); err == nil {
+				return nil, Errorf(ErrAlreadyInstalled, "alias '%s' is already installed. Use 'lnb unalias %s' first to reinstall", aliasName, aliasName)
 			} else {
 				// Config says it's installed but file doesn't exist - clean up the config
-				fmt.Printf("Warning: Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.\n", aliasName, entry.TargetPath)
+				result.warnf("Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.", aliasName, entry.TargetPath)
 				cfg.RemoveEntry(aliasName)
 				if err := cfg.Save(); err != nil {
-					fmt.Printf("Warning: failed to clean up config: %v\n", err)
+					result.warnf("failed to clean up config: %v", err)
 				}
 			}
 		}
 
 		// Check if the target path already exists
-		if _, err := os.Stat(scriptPath); err == nil {
-			return fmt.Errorf("file already exists at %s. Please remove it manually or use 'lnb unalias %s' if it was installed by LNB", scriptPath, aliasName)
+		if _, err := fsops.Stat(scriptPath); err == nil {
+			return nil, Errorf(ErrTargetExists, "file already exists at %s. Please remove it manually or use 'lnb unalias %s' if it was installed by LNB", scriptPath, aliasName)
 		}
 
-		// Convert relative paths to absolute paths in the command
-		convertedCommand := h.convertRelativePaths(command)
-
 		// Create the shell script content
-		scriptContent := fmt.Sprintf(`#!/bin/bash
-%s "$@"
-`, convertedCommand)
+		scriptContent := unixScript(run)
+
+		if err := fsops.MkdirAll(h.BinDir(), 0755); err != nil {
+			return nil, fmt.Errorf("error creating bin dir: %v", err)
+		}
 
 		// Write the script file
-		err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
+		err := fsops.WriteFile(scriptPath, []byte(scriptContent), 0755)
 		if err != nil {
-			return fmt.Errorf("failed to create alias script: %v", err)
+			return nil, fmt.Errorf("failed to create alias script: %v", err)
 		}
 
-		fmt.Printf("Created alias: %s -> %s\n", aliasName, convertedCommand)
+		result.Command = run.String()
 
-		// Add to config with special marker for aliases
-		cfg.AddEntry(aliasName, "alias:"+command, scriptPath)
+		// Add to config
+		entry := cfg.AddAlias(aliasName, command, scriptPath)
+		recordAlias(entry, opts)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 
 	case "remove":
 		// Check if this alias was installed by LNB
 		entry, exists := cfg.GetEntry(aliasName)
 		if !exists {
-			return fmt.Errorf("alias '%s' was not installed by LNB", aliasName)
+			return nil, Errorf(ErrNotInstalled, "alias '%s' was not installed by LNB", aliasName)
 		}
 
 		// Verify the target path matches what we expect
+		scriptPath = entryTarget(entry, aliasName)
+		result.TargetPath = scriptPath
 		if entry.TargetPath != scriptPath {
-			return fmt.Errorf("alias '%s' target path mismatch: expected %s, found %s", aliasName, scriptPath, entry.TargetPath)
+			return nil, Errorf(ErrTargetMismatch, "alias '%s' target path mismatch: expected %s, found %s", aliasName, scriptPath, entry.TargetPath)
 		}
 
-		err := os.Remove(scriptPath)
-		if err != nil {
-			return fmt.Errorf("failed to remove alias: %v", err)
+		if err := removeTargets(entry, result); err != nil {
+			return nil, fmt.Errorf("failed to remove alias: %v", err)
 		}
-		fmt.Printf("Removed alias: %s\n", aliasName)
 
 		// Remove from config
 		cfg.RemoveEntry(aliasName)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 	}
-	return nil
+	return result, nil
 }
 
-// convertRelativePaths converts relative paths in command to absolute paths
-func (h *linuxHandler) convertRelativePaths(command string) string {
-	args := parseShellArgsLinux(command)
-
-	for i, arg := range args {
-		// Remove quotes temporarily to check the path, but preserve them in the final result
-		unquotedArg := arg
-		hasQuotes := false
-		var quoteChar string
-
-		if (strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`)) ||
-			(strings.HasPrefix(arg, `'`) && strings.HasSuffix(arg, `'`)) {
-			hasQuotes = true
-			quoteChar = string(arg[0])
-			unquotedArg = arg[1 : len(arg)-1]
-		}
-
-		// Check if this looks like a relative path
-		if strings.HasPrefix(unquotedArg, "./") || strings.HasPrefix(unquotedArg, "../") ||
-			(strings.Contains(unquotedArg, ".") && !strings.HasPrefix(unquotedArg, "/") && !strings.Contains(unquotedArg, "://")) {
-			if absPath, err := filepath.Abs(unquotedArg); err == nil {
-				// Verify the file exists before converting
-				if _, err := os.Stat(absPath); err == nil {
-					if hasQuotes {
-						args[i] = quoteChar + absPath + quoteChar
-					} else {
-						args[i] = absPath
-					}
-				}
-			}
-		}
+// Files renders an alias's script, a Windows program's wrapper, or a binary's symlink
+func (h *linuxHandler) Files(entry *config.LnbEntry) []File {
+	switch {
+	case entry.Command != nil:
+		return []File{{Path: entry.TargetPath, Content: unixScript(aliasCommand(entry))}}
+	case entry.Kind == config.KindShim:
+		return []File{{Path: entry.TargetPath, Content: wsl.Wrapper(entry.SourcePath, os.Getenv("WSL_DISTRO_NAME"))}}
 	}
-
-	return reconstructCommandLinux(args)
+	return []File{{Path: entry.TargetPath, Link: entry.SourcePath}}
 }
 
 // checkExecutable verifies if a file is executable
 func (h *linuxHandler) checkExecutable(path string) error {
-	fileInfo, err := os.Stat(path)
+	fileInfo, err := fsops.Stat(path)
 	if err != nil {
 		return err
 	}
@@ -274,11 +282,3 @@ func (h *linuxHandler) checkExecutable(path string) error {
 
 	return nil
 }
-
-// validateCommand does basic validation since path resolution is handled by main handler
-func (h *linuxHandler) validateCommand(command string) error {
-	if strings.TrimSpace(command) == "" {
-		return fmt.Errorf("empty command")
-	}
-	return nil
-}
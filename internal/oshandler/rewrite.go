@@ -0,0 +1,183 @@
+package oshandler
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"lnb/internal/config"
+	"lnb/internal/desktop"
+	"lnb/internal/fsops"
+)
+
+// Rewrite turns the installed entry old into entry, which is a changed copy
+// of it: another name, command or set of launchers. The files stay in the bin
+// dir old was created in. A file whose name changes is renamed, and a file is
+// only written if what it should hold changes. The config entry is replaced by
+// entry. If a file or the config cannot be changed, the files already changed
+// are put back.
+func Rewrite(h Handler, old, entry *config.LnbEntry) (*Result, error) {
+	entry.TargetPath = entryTarget(old, entry.Name)
+	files := h.Files(entry)
+	entry.TargetPath = files[0].Path
+	entry.ExtraTargets = nil
+	for _, file := range files[1:] {
+		entry.ExtraTargets = append(entry.ExtraTargets, file.Path)
+	}
+
+	result := &Result{Name: entry.Name, SourcePath: entry.SourcePath, TargetPath: entry.TargetPath}
+	if entry.Command != nil {
+		result.Command = aliasCommand(entry).String()
+	}
+
+	// Menu entries are not launchers; they are made again for a new name
+	oldPaths := []string{old.TargetPath}
+	if !old.Desktop {
+		oldPaths = append(oldPaths, old.ExtraTargets...)
+	}
+	used := make(map[string]bool)
+	kept := make(map[string]bool)
+	for _, file := range files {
+		kept[file.Path] = true
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+
+	var undo []func()
+	rollBack := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+	for i, file := range files {
+		if err := rewriteFile(file, oldFile(oldPaths, i, file.Path, kept, used), &undo); err != nil {
+			rollBack()
+			return nil, err
+		}
+	}
+
+	newDesktop := old.Desktop && entry.Name != old.Name
+	if newDesktop {
+		created, warnings, err := desktop.Install(entry.Name, entry.TargetPath, entry.SourcePath)
+		if err != nil {
+			rollBack()
+			return nil, fmt.Errorf("failed to create desktop entry: %v", err)
+		}
+		undo = append(undo, func() {
+			for _, path := range created {
+				fsops.Remove(path)
+			}
+			desktop.Removed(created[0])
+		})
+		result.Warnings = append(result.Warnings, warnings...)
+		entry.ExtraTargets = created
+	}
+
+	cfg.RemoveEntry(old.Name)
+	cfg.Entries[entry.Name] = entry
+	if err := cfg.Save(); err != nil {
+		rollBack()
+		return nil, fmt.Errorf("failed to update config: %v", err)
+	}
+
+	// What the entry no longer has goes once the config no longer lists it
+	var removed []string
+	if newDesktop {
+		removed = append(removed, old.ExtraTargets...)
+	}
+	for _, path := range oldPaths {
+		if !used[path] && !kept[path] {
+			removed = append(removed, path)
+		}
+	}
+	for _, path := range removed {
+		if err := fsops.Remove(path); err != nil && !os.IsNotExist(err) {
+			result.warnf("failed to remove %s: %v", path, err)
+		}
+	}
+	if newDesktop && len(old.ExtraTargets) > 0 {
+		desktop.Removed(old.ExtraTargets[0])
+	}
+	return result, nil
+}
+
+// oldFile returns the existing launcher the i-th new launcher at path comes
+// from: the one already at path, else the old launcher in the same place in
+// the list if no new launcher keeps its path, else "" for a launcher that is
+// new. The launcher returned is marked as used.
+func oldFile(oldPaths []string, i int, path string, kept, used map[string]bool) string {
+	for _, oldPath := range oldPaths {
+		if oldPath == path {
+			used[oldPath] = true
+			return oldPath
+		}
+	}
+	if i < len(oldPaths) && !kept[oldPaths[i]] && !used[oldPaths[i]] {
+		used[oldPaths[i]] = true
+		return oldPaths[i]
+	}
+	return ""
+}
+
+// rewriteFile makes file out of the launcher at from, which may be another
+// path or "" if there is none, and adds how to put things back to undo
+func rewriteFile(file File, from string, undo *[]func()) error {
+	if from != file.Path {
+		if info, err := fsops.Lstat(file.Path); err == nil && !sameFile(from, info) {
+			return Errorf(ErrTargetExists, "file already exists at %s", file.Path)
+		}
+	}
+
+	if from == "" {
+		var err error
+		if file.Link != "" {
+			err = fsops.Symlink(file.Link, file.Path)
+		} else {
+			err = fsops.WriteFile(file.Path, []byte(file.Content), 0755)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", file.Path, err)
+		}
+		*undo = append(*undo, func() { fsops.Remove(file.Path) })
+		return nil
+	}
+
+	if from != file.Path {
+		if err := fsops.Rename(from, file.Path); err != nil {
+			return fmt.Errorf("failed to rename %s: %v", from, err)
+		}
+		*undo = append(*undo, func() { fsops.Rename(file.Path, from) })
+	}
+
+	if file.Link != "" {
+		if target, err := fsops.Readlink(file.Path); err == nil && target == file.Link {
+			return nil
+		}
+		return fmt.Errorf("%s no longer links to %s", file.Path, file.Link)
+	}
+	before, err := fsops.ReadFile(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", file.Path, err)
+	}
+	if string(before) == file.Content {
+		return nil
+	}
+	if err := fsops.WriteAtomic(file.Path, []byte(file.Content), 0755); err != nil {
+		return fmt.Errorf("failed to rewrite %s: %v", file.Path, err)
+	}
+	*undo = append(*undo, func() { fsops.WriteAtomic(file.Path, before, 0755) })
+	return nil
+}
+
+// sameFile reports whether info describes the file at path, as it does when
+// a name changes only in case on a case-insensitive file system
+func sameFile(path string, info fs.FileInfo) bool {
+	if path == "" {
+		return false
+	}
+	other, err := fsops.Lstat(path)
+	return err == nil && os.SameFile(other, info)
+}
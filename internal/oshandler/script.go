@@ -0,0 +1,218 @@
+package oshandler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/winpath"
+)
+
+// unixScript renders the bash wrapper that runs an alias command
+func unixScript(command *config.Command) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/bash\n")
+
+	if command.Dir != "" {
+		fmt.Fprintf(&b, "cd %s || exit 1\n", config.QuoteWord(command.Dir))
+	}
+	for _, key := range command.EnvKeys() {
+		fmt.Fprintf(&b, "export %s=%s\n", key, config.QuoteWord(command.Env[key]))
+	}
+
+	fmt.Fprintf(&b, "%s \"$@\"\n", command.String())
+	return b.String()
+}
+
+// posixMountProbe sets $lnb_mount to where the shell running a POSIX shim on
+// Windows mounts drive letters
+const posixMountProbe = `# Drives are under /mnt on WSL, /cygdrive on Cygwin and / on MSYS and Git Bash
+case "$(uname -s)" in
+    CYGWIN*) lnb_mount=` + winpath.MountCygwin + ` ;;
+    *) if [ -n "$WSL_DISTRO_NAME" ] || [ -e /proc/sys/fs/binfmt_misc/WSLInterop ]; then lnb_mount=` + winpath.MountWSL + `; else lnb_mount=` + winpath.MountMSYS + `; fi ;;
+esac
+`
+
+// posixScript renders the extensionless sh wrapper that runs a Windows alias
+// command or binary from Git Bash, MSYS, Cygwin or WSL. The program and
+// working directory are translated to the shell's drive form at run time;
+// arguments and env values are left alone since the Windows program reads
+// them. Shell-mode lines are run by sh, like on Unix.
+func posixScript(command *config.Command) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Generated by lnb. Changes are overwritten when the entry is recreated.\n")
+
+	dir, dirOnDrive := posixWord(command.Dir)
+	program, programOnDrive := "", false
+	if !command.Shell {
+		program, programOnDrive = posixWord(command.Program())
+	}
+	if dirOnDrive || programOnDrive {
+		b.WriteString(posixMountProbe)
+	}
+
+	if command.Dir != "" {
+		fmt.Fprintf(&b, "cd %s || exit 1\n", dir)
+	}
+	for _, key := range command.EnvKeys() {
+		fmt.Fprintf(&b, "export %s=%s\n", key, config.QuoteWord(command.Env[key]))
+	}
+
+	if command.Shell {
+		fmt.Fprintf(&b, "%s \"$@\"\n", command.Program())
+		return b.String()
+	}
+	words := []string{program}
+	for _, arg := range command.Argv[1:] {
+		words = append(words, config.QuoteWord(arg))
+	}
+	fmt.Fprintf(&b, "%s \"$@\"\n", strings.Join(words, " "))
+	return b.String()
+}
+
+// posixWord quotes a Windows path for a POSIX shim, prefixing absolute paths
+// with $lnb_mount. It reports whether the path needs the mount probe.
+func posixWord(path string) (string, bool) {
+	if posix, ok := winpath.ToPosix(path, ""); ok {
+		if strings.HasPrefix(posix, "//") {
+			return config.QuoteWord(posix), false
+		}
+		return `"$lnb_mount"` + config.QuoteWord(posix), true
+	}
+	return config.QuoteWord(strings.ReplaceAll(path, `\`, "/")), false
+}
+
+// batchScript renders the .bat wrapper that runs an alias command
+func batchScript(command *config.Command) string {
+	var b strings.Builder
+	b.WriteString("@echo off\n")
+
+	// Keep cd and set from leaking into the calling cmd session
+	if command.Dir != "" || len(command.Env) > 0 {
+		b.WriteString("setlocal\n")
+	}
+	if command.Dir != "" {
+		fmt.Fprintf(&b, "cd /d \"%s\" || exit /b 1\n", batchEscape(command.Dir))
+	}
+	for _, key := range command.EnvKeys() {
+		fmt.Fprintf(&b, "set \"%s=%s\"\n", key, batchEscape(command.Env[key]))
+	}
+
+	if command.Shell {
+		fmt.Fprintf(&b, "%s %%*\n", cmdLine(command.Program()))
+	} else {
+		quoted := make([]string, len(command.Argv))
+		for i, arg := range command.Argv {
+			quoted[i] = batchQuote(arg)
+		}
+		fmt.Fprintf(&b, "%s %%*\n", strings.Join(quoted, " "))
+	}
+	return b.String()
+}
+
+// cmdBinaryScript renders the .cmd wrapper that runs a binary
+func cmdBinaryScript(absPath string) string {
+	return fmt.Sprintf("@echo off\n\"%s\" %%*\n", absPath)
+}
+
+// powershellScript renders the .ps1 wrapper that runs an alias command or,
+// for a bare NewCommand, a binary. Arguments are splatted from $args so they
+// reach the program unchanged, and the program's exit code is passed on.
+// Shell-mode lines are cmd.exe syntax and are handed to %ComSpec%.
+func powershellScript(command *config.Command) string {
+	var b strings.Builder
+	b.WriteString("# Generated by lnb. Changes are overwritten when the entry is recreated.\n")
+
+	// A .ps1 runs in the caller's session, so undo cd and env changes afterwards
+	scoped := command.Dir != "" || len(command.Env) > 0
+	indent := ""
+	if scoped {
+		indent = "    "
+		if command.Dir != "" {
+			fmt.Fprintf(&b, "Push-Location -LiteralPath %s -ErrorAction Stop\n", psQuote(command.Dir))
+		}
+		if keys := command.EnvKeys(); len(keys) > 0 {
+			b.WriteString("$lnbSavedEnv = @{}\n")
+			for _, key := range keys {
+				fmt.Fprintf(&b, "$lnbSavedEnv[%s] = $env:%s\n", psQuote(key), key)
+				fmt.Fprintf(&b, "$env:%s = %s\n", key, psQuote(command.Env[key]))
+			}
+		}
+		b.WriteString("try {\n")
+	}
+
+	if command.Shell {
+		fmt.Fprintf(&b, "%s$line = %s\n", indent, psQuote(cmdLine(command.Program())))
+		fmt.Fprintf(&b, "%sforeach ($arg in $args) { $line += ' \"' + ($arg -replace '\"', '\"\"') + '\"' }\n", indent)
+		fmt.Fprintf(&b, "%s& $env:ComSpec /d /s /c $line\n", indent)
+	} else {
+		quoted := make([]string, len(command.Argv))
+		for i, arg := range command.Argv {
+			quoted[i] = psQuote(arg)
+		}
+		fmt.Fprintf(&b, "%s& %s @args\n", indent, strings.Join(quoted, " "))
+	}
+
+	if scoped {
+		b.WriteString("} finally {\n")
+		if len(command.Env) > 0 {
+			b.WriteString("    foreach ($key in $lnbSavedEnv.Keys) { [Environment]::SetEnvironmentVariable($key, $lnbSavedEnv[$key]) }\n")
+		}
+		if command.Dir != "" {
+			b.WriteString("    Pop-Location\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("exit $LASTEXITCODE\n")
+	return b.String()
+}
+
+// psQuote quotes a string as a PowerShell verbatim string. PowerShell also
+// treats typographic single quotes as delimiters, so those are doubled too.
+func psQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\u2018', '\u2019', '\u201a', '\u201b':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// homeWord matches a ~ that starts a word, which cmd.exe does not expand
+var homeWord = regexp.MustCompile(`(^|[\s"])~([/\\\s"]|$)`)
+
+// cmdLine prepares a shell-mode line for cmd.exe: a ~ starting a word becomes
+// %USERPROFILE%, so it means the home directory at run time as it does in sh
+func cmdLine(line string) string {
+	return homeWord.ReplaceAllString(line, "${1}%USERPROFILE%${2}")
+}
+
+// batchEscape escapes % so cmd.exe does not expand it inside a batch file
+func batchEscape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// batchQuote quotes an argument for a batch file command line if it needs quoting
+func batchQuote(arg string) string {
+	arg = batchEscape(arg)
+	if arg != "" && !strings.ContainsAny(arg, " \t&|<>^()\",;=") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+}
+
+// validateCommand does basic validation since path resolution is handled by the caller
+func validateCommand(command *config.Command) error {
+	if command == nil || strings.TrimSpace(command.Program()) == "" {
+		return fmt.Errorf("empty command")
+	}
+	return nil
+}
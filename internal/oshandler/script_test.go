@@ -0,0 +1,179 @@
+package oshandler
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"lnb/internal/config"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites the file with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got ---\n%s--- want ---\n%s", name, got, want)
+	}
+}
+
+var scriptCommands = []struct {
+	name    string
+	command *config.Command
+}{
+	{"binary", config.NewCommand(`C:\Program Files\Tool\tool.exe`)},
+	{"exec", &config.Command{Argv: []string{`C:\Tools\rg.exe`, "--glob", "*.go", "it's 100%"}}},
+	{"shell", &config.Command{Argv: []string{`dir /b | findstr "%USERNAME%"`}, Shell: true}},
+	{"scoped", &config.Command{
+		Argv: []string{`C:\Tools\deploy.exe`, "--env", "prod"},
+		Env:  map[string]string{"STAGE": "prod", "TOKEN": "a'b%c"},
+		Dir:  `C:\work\app`,
+	}},
+}
+
+func TestPowershellScript(t *testing.T) {
+	for _, tc := range scriptCommands {
+		t.Run(tc.name, func(t *testing.T) {
+			checkGolden(t, tc.name+".ps1", powershellScript(tc.command))
+		})
+	}
+}
+
+func TestBatchScript(t *testing.T) {
+	for _, tc := range scriptCommands {
+		t.Run(tc.name, func(t *testing.T) {
+			checkGolden(t, tc.name+".bat", batchScript(tc.command))
+		})
+	}
+}
+
+func TestCmdBinaryScript(t *testing.T) {
+	checkGolden(t, "binary.cmd", cmdBinaryScript(`C:\Program Files\Tool\tool.exe`))
+}
+
+func TestPsQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":         "'plain'",
+		"it's":          "'it''s'",
+		"$env:HOME `x`": "'$env:HOME `x`'",
+		"it\u2019s":     "'it\u2019\u2019s'",
+	}
+	for in, want := range tests {
+		if got := psQuote(in); got != want {
+			t.Errorf("psQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCmdLine(t *testing.T) {
+	tests := map[string]string{
+		"type ~/notes.txt":       "type %USERPROFILE%/notes.txt",
+		`dir "~\docs" ~`:         `dir "%USERPROFILE%\docs" %USERPROFILE%`,
+		"echo a~b ~user foo.bar": "echo a~b ~user foo.bar",
+	}
+	for in, want := range tests {
+		if got := cmdLine(in); got != want {
+			t.Errorf("cmdLine(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPosixScript(t *testing.T) {
+	for _, tc := range scriptCommands {
+		t.Run(tc.name, func(t *testing.T) {
+			checkGolden(t, tc.name+".sh", posixScript(tc.command))
+		})
+	}
+
+	t.Run("bare_program", func(t *testing.T) {
+		checkGolden(t, "bare.sh", posixScript(&config.Command{Argv: []string{"git", "status", "-s"}}))
+	})
+}
+
+func TestShimFiles(t *testing.T) {
+	dir := filepath.Join("bin")
+	tests := []struct {
+		opts Options
+		want []string
+	}{
+		{Options{}, []string{"tool.bat"}},
+		{Options{Shim: config.ShimCmd}, []string{"tool.bat"}},
+		{Options{Shim: config.ShimPS1}, []string{"tool.ps1"}},
+		{Options{Shim: config.ShimBoth}, []string{"tool.bat", "tool.ps1"}},
+		{Options{Shim: config.ShimBoth, Posix: true}, []string{"tool.bat", "tool.ps1", "tool"}},
+		{Options{Posix: true}, []string{"tool.bat", "tool"}},
+	}
+	for _, tc := range tests {
+		got := shimFiles(dir, "tool", ".bat", tc.opts, nil, nil)
+		if len(got) != len(tc.want) {
+			t.Fatalf("shimFiles(%+v) = %v, want %v", tc.opts, got, tc.want)
+		}
+		for i := range got {
+			if got[i].path != filepath.Join(dir, tc.want[i]) {
+				t.Errorf("shimFiles(%+v)[%d] = %s, want %s", tc.opts, i, got[i].path, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestWindowsFiles(t *testing.T) {
+	dir := filepath.Join("old", "bin")
+	command := &config.Command{Argv: []string{"git", "status"}}
+	entry := &config.LnbEntry{
+		Name:       "gs",
+		Kind:       config.KindAlias,
+		Command:    command,
+		TargetPath: filepath.Join(dir, "gs.bat"),
+		Shim:       config.ShimBoth,
+		PosixShim:  true,
+	}
+
+	files := (&windowsHandler{}).Files(entry)
+	want := []File{
+		{Path: filepath.Join(dir, "gs.bat"), Content: batchScript(command)},
+		{Path: filepath.Join(dir, "gs.ps1"), Content: powershellScript(command)},
+		{Path: filepath.Join(dir, "gs"), Content: posixScript(command)},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("Files() returned %d files, want %d", len(files), len(want))
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("Files()[%d] = %+v, want %+v", i, files[i], want[i])
+		}
+	}
+}
+
+func TestCommandFor(t *testing.T) {
+	command := &config.Command{Argv: []string{"xdg-open"}}
+	opts := Options{Variants: map[string]*config.Command{"darwin": {Argv: []string{"open"}}}}
+	if got := commandFor(command, opts, "darwin"); got.Program() != "open" {
+		t.Errorf("commandFor(darwin) = %s, want the darwin variant", got)
+	}
+	if got := commandFor(command, opts, "linux"); got != command {
+		t.Errorf("commandFor(linux) = %s, want the alias's own command", got)
+	}
+
+	entry := &config.LnbEntry{Kind: config.KindAlias, Command: command, Variants: opts.Variants}
+	want := command
+	if runtime.GOOS == "darwin" {
+		want = opts.Variants["darwin"]
+	}
+	if got := aliasCommand(entry); got != want {
+		t.Errorf("aliasCommand() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,3 @@
+#!/bin/sh
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+git status -s "$@"
@@ -0,0 +1,2 @@
+@echo off
+"C:\Program Files\Tool\tool.exe" %*
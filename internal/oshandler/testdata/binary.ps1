@@ -0,0 +1,3 @@
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+& 'C:\Program Files\Tool\tool.exe' @args
+exit $LASTEXITCODE
@@ -0,0 +1,2 @@
+@echo off
+C:\Tools\rg.exe --glob *.go "it's 100%%" %*
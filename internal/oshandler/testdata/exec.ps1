@@ -0,0 +1,3 @@
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+& 'C:\Tools\rg.exe' '--glob' '*.go' 'it''s 100%' @args
+exit $LASTEXITCODE
@@ -0,0 +1,8 @@
+#!/bin/sh
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+# Drives are under /mnt on WSL, /cygdrive on Cygwin and / on MSYS and Git Bash
+case "$(uname -s)" in
+    CYGWIN*) lnb_mount=/cygdrive ;;
+    *) if [ -n "$WSL_DISTRO_NAME" ] || [ -e /proc/sys/fs/binfmt_misc/WSLInterop ]; then lnb_mount=/mnt; else lnb_mount=; fi ;;
+esac
+"$lnb_mount"/c/Tools/rg.exe --glob '*.go' 'it'\''s 100%' "$@"
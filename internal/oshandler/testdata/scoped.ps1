@@ -0,0 +1,14 @@
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+Push-Location -LiteralPath 'C:\work\app' -ErrorAction Stop
+$lnbSavedEnv = @{}
+$lnbSavedEnv['STAGE'] = $env:STAGE
+$env:STAGE = 'prod'
+$lnbSavedEnv['TOKEN'] = $env:TOKEN
+$env:TOKEN = 'a''b%c'
+try {
+    & 'C:\Tools\deploy.exe' '--env' 'prod' @args
+} finally {
+    foreach ($key in $lnbSavedEnv.Keys) { [Environment]::SetEnvironmentVariable($key, $lnbSavedEnv[$key]) }
+    Pop-Location
+}
+exit $LASTEXITCODE
@@ -0,0 +1,2 @@
+@echo off
+dir /b | findstr "%USERNAME%" %*
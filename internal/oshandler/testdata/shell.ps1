@@ -0,0 +1,5 @@
+# Generated by lnb. Changes are overwritten when the entry is recreated.
+$line = 'dir /b | findstr "%USERNAME%"'
+foreach ($arg in $args) { $line += ' "' + ($arg -replace '"', '""') + '"' }
+& $env:ComSpec /d /s /c $line
+exit $LASTEXITCODE
@@ -3,333 +3,363 @@ package oshandler
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/winpath"
+	"lnb/internal/wsl"
 )
 
-// parseShellArgsWindows parses a command string into arguments while respecting quotes
-func parseShellArgsWindows(command string) []string {
-	var args []string
-	var current strings.Builder
-	var inQuotes bool
-	var quoteChar rune
-
-	for _, char := range command {
-		switch char {
-		case '"', '\'':
-			if !inQuotes {
-				inQuotes = true
-				quoteChar = char
-				current.WriteRune(char) // Keep the quote in the argument
-			} else if char == quoteChar {
-				inQuotes = false
-				current.WriteRune(char) // Keep the closing quote
-				quoteChar = 0
-			} else {
-				current.WriteRune(char)
-			}
-		case ' ', '\t':
-			if inQuotes {
-				current.WriteRune(char)
-			} else {
-				if current.Len() > 0 {
-					args = append(args, current.String())
-					current.Reset()
-				}
-			}
-		default:
-			current.WriteRune(char)
-		}
-	}
-
-	if current.Len() > 0 {
-		args = append(args, current.String())
-	}
+type windowsHandler struct{}
 
-	return args
+// BinDir returns the directory .cmd and .bat wrappers are written to
+func (h *windowsHandler) BinDir() string {
+	return binDirOr(filepath.Join(os.Getenv("USERPROFILE"), "bin"))
 }
 
-// reconstructCommandWindows rebuilds a command string from parsed arguments
-func reconstructCommandWindows(args []string) string {
-	return strings.Join(args, " ")
+// LinkName returns the name a binary is installed under by default
+func (h *windowsHandler) LinkName(absPath string) string {
+	linkName := filepath.Base(absPath)
+	return strings.TrimSuffix(linkName, filepath.Ext(linkName))
 }
 
-type windowsHandler struct{}
-
-func (h *windowsHandler) Handle(absPath, action string) error {
-	binDir := filepath.Join(os.Getenv("USERPROFILE"), "bin")
-	linkName := filepath.Base(absPath)
-	linkNameWithoutExt := strings.TrimSuffix(linkName, filepath.Ext(linkName))
-	cmdPath := filepath.Join(binDir, linkNameWithoutExt+".cmd")
+// Handle installs or removes a binary. The wrapper is called name, or the
+// binary's base name without its extension when name is empty.
+func (h *windowsHandler) Handle(absPath, name, action string, opts Options) (*Result, error) {
+	binDir := h.BinDir()
+	linkNameWithoutExt := name
+	if linkNameWithoutExt == "" {
+		linkNameWithoutExt = h.LinkName(absPath)
+	}
+	shims, inWSL := binaryShims(binDir, linkNameWithoutExt, absPath, opts)
+	result := &Result{Name: linkNameWithoutExt, SourcePath: absPath, TargetPath: shims[0].path}
 
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
 	switch action {
 	case "install":
 		// Check if file exists
-		if _, err := os.Stat(absPath); os.IsNotExist(err) {
-			return fmt.Errorf("file '%s' does not exist", absPath)
+		if _, err := fsops.Stat(absPath); os.IsNotExist(err) {
+			return nil, Errorf(ErrNotExist, "file '%s' does not exist", absPath)
+		}
+
+		if err := checkNewName(cfg, linkNameWithoutExt); err != nil {
+			return nil, err
 		}
 
 		// Check if this binary is already installed
 		if entry, exists := cfg.GetEntry(linkNameWithoutExt); exists {
 			// Verify the target file actually exists
-			if _, err := os.Stat(entry.TargetPath); err == nil {
-				return fmt.Errorf("binary '%s' is already installed. Use 'lnb remove %s' first to reinstall", linkNameWithoutExt, linkNameWithoutExt)
+			if _, err := fsops.Stat(entry.TargetPath); err == nil {
+				return nil, Errorf(ErrAlreadyInstalled, "binary '%s' is already installed. Use 'lnb remove %s' first to reinstall", linkNameWithoutExt, linkNameWithoutExt)
 			} else {
 				// Config says it's installed but file doesn't exist - clean up the config
-				fmt.Printf("Warning: Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.\n", linkNameWithoutExt, entry.TargetPath)
+				result.warnf("Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.", linkNameWithoutExt, entry.TargetPath)
 				cfg.RemoveEntry(linkNameWithoutExt)
 				if err := cfg.Save(); err != nil {
-					fmt.Printf("Warning: failed to clean up config: %v\n", err)
+					result.warnf("failed to clean up config: %v", err)
 				}
 			}
 		}
 
-		// Check if the target path already exists
-		if _, err := os.Stat(cmdPath); err == nil {
-			return fmt.Errorf("file already exists at %s. Please remove it manually or use 'lnb remove %s' if it was installed by LNB", cmdPath, linkNameWithoutExt)
+		// Check if the target paths already exist
+		for _, shim := range shims {
+			if _, err := fsops.Stat(shim.path); err == nil {
+				return nil, Errorf(ErrTargetExists, "file already exists at %s. Please remove it manually or use 'lnb remove %s' if it was installed by LNB", shim.path, linkNameWithoutExt)
+			}
 		}
 
-		err := os.MkdirAll(binDir, 0755)
+		err := fsops.MkdirAll(binDir, 0755)
 		if err != nil {
-			return fmt.Errorf("error creating bin dir: %v", err)
+			return nil, fmt.Errorf("error creating bin dir: %v", err)
 		}
 
-		cmdContents := fmt.Sprintf(`@echo off
-"%s" %%*
-`, absPath)
-
-		err = os.WriteFile(cmdPath, []byte(cmdContents), 0755)
-		if err != nil {
-			return fmt.Errorf("failed to write wrapper: %v", err)
+		if err := writeShims(shims); err != nil {
+			return nil, fmt.Errorf("failed to write wrapper: %v", err)
 		}
-		fmt.Printf("Installed: %s\n", cmdPath)
+		noteShims(result, opts.Shim)
 
 		// Automatically ensure the bin directory is in PATH
-		h.ensureInPath(binDir)
+		h.ensureInPath(binDir, result)
 
 		// Add to config
-		cfg.AddEntry(linkNameWithoutExt, absPath, cmdPath)
+		entry := cfg.AddBinary(linkNameWithoutExt, absPath, shims[0].path, config.ModeWrapper)
+		recordShims(entry, shims, opts)
+		recordEntry(entry, opts)
+		if inWSL {
+			entry.Kind = config.KindShim
+		}
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 
 	case "remove":
 		// Check if this binary was installed by LNB
 		entry, exists := cfg.GetEntry(linkNameWithoutExt)
 		if !exists {
-			return fmt.Errorf("binary '%s' was not installed by LNB", linkNameWithoutExt)
+			return nil, Errorf(ErrNotInstalled, "binary '%s' was not installed by LNB", linkNameWithoutExt)
 		}
 
 		// Verify the target path matches what we expect
-		if entry.TargetPath != cmdPath {
-			return fmt.Errorf("binary '%s' target path mismatch: expected %s, found %s", linkNameWithoutExt, cmdPath, entry.TargetPath)
+		expected := shimTarget(entry, ".cmd")
+		result.TargetPath = expected
+		if entry.TargetPath != expected {
+			return nil, Errorf(ErrTargetMismatch, "binary '%s' target path mismatch: expected %s, found %s", linkNameWithoutExt, expected, entry.TargetPath)
 		}
 
-		err := os.Remove(cmdPath)
-		if err != nil {
-			return fmt.Errorf("failed to remove: %v", err)
+		if err := removeTargets(entry, result); err != nil {
+			return nil, fmt.Errorf("failed to remove: %v", err)
 		}
-		fmt.Printf("Removed: %s\n", cmdPath)
 
 		// Remove from config
 		cfg.RemoveEntry(linkNameWithoutExt)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 	}
-	return nil
+	return result, nil
 }
 
-func (h *windowsHandler) HandleAlias(aliasName, command, action string) error {
-	binDir := filepath.Join(os.Getenv("USERPROFILE"), "bin")
-	batPath := filepath.Join(binDir, aliasName+".bat")
+func (h *windowsHandler) HandleAlias(aliasName string, command *config.Command, action string, opts Options) (*Result, error) {
+	binDir := h.BinDir()
+	result := &Result{Name: aliasName, TargetPath: filepath.Join(binDir, aliasName+".bat")}
 
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
 	switch action {
 	case "install":
+		// An alias with a variant for this OS runs that instead
+		run := commandFor(command, opts, "windows")
+
 		// Validate the command
-		if err := h.validateCommand(command); err != nil {
-			return fmt.Errorf("invalid command '%s': %v", command, err)
+		if err := validateCommand(run); err != nil {
+			return nil, Errorf(ErrInvalidCommand, "invalid command '%s': %v", run, err)
+		}
+
+		if err := checkNewName(cfg, aliasName); err != nil {
+			return nil, err
 		}
 
 		// Check if this alias is already installed
 		if entry, exists := cfg.GetEntry(aliasName); exists {
 			// Verify the target file actually exists
-			if _, err := os.Stat(entry.TargetPath); err == nil {
-				return fmt.Errorf("alias '%s' is already installed. Use 'lnb unalias %s' first to reinstall", aliasName, aliasName)
+			if _, err := fsops.Stat(entry.TargetPath); err == nil {
+				return nil, Errorf(ErrAlreadyInstalled, "alias '%s' is already installed. Use 'lnb unalias %s' first to reinstall", aliasName, aliasName)
 			} else {
 				// Config says it's installed but file doesn't exist - clean up the config
-				fmt.Printf("Warning: Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.\n", aliasName, entry.TargetPath)
+				result.warnf("Config shows '%s' as installed but target file '%s' doesn't exist. Cleaning up config entry.", aliasName, entry.TargetPath)
 				cfg.RemoveEntry(aliasName)
 				if err := cfg.Save(); err != nil {
-					fmt.Printf("Warning: failed to clean up config: %v\n", err)
+					result.warnf("failed to clean up config: %v", err)
 				}
 			}
 		}
 
-		// Check if the target path already exists
-		if _, err := os.Stat(batPath); err == nil {
-			return fmt.Errorf("file already exists at %s. Please remove it manually or use 'lnb unalias %s' if it was installed by LNB", batPath, aliasName)
+		// Check if the target paths already exist
+		shims := shimFiles(binDir, aliasName, ".bat", opts, func() string { return batchScript(run) }, run)
+		result.TargetPath = shims[0].path
+
+		for _, shim := range shims {
+			if _, err := fsops.Stat(shim.path); err == nil {
+				return nil, Errorf(ErrTargetExists, "file already exists at %s. Please remove it manually or use 'lnb unalias %s' if it was installed by LNB", shim.path, aliasName)
+			}
 		}
 
-		err := os.MkdirAll(binDir, 0755)
+		err := fsops.MkdirAll(binDir, 0755)
 		if err != nil {
-			return fmt.Errorf("error creating bin dir: %v", err)
+			return nil, fmt.Errorf("error creating bin dir: %v", err)
 		}
 
-		// Convert relative paths to absolute paths in the command
-		convertedCommand := h.convertRelativePaths(command)
-
-		// Create the batch file content
-		batContent := fmt.Sprintf(`@echo off
-%s %%*
-`, convertedCommand)
-
-		// Write the batch file
-		err = os.WriteFile(batPath, []byte(batContent), 0755)
-		if err != nil {
-			return fmt.Errorf("failed to create alias batch file: %v", err)
+		if err := writeShims(shims); err != nil {
+			return nil, fmt.Errorf("failed to create alias wrapper: %v", err)
 		}
+		noteShims(result, opts.Shim)
 
-		fmt.Printf("Created alias: %s -> %s\n", aliasName, convertedCommand)
+		result.Command = run.String()
 
 		// Automatically ensure the bin directory is in PATH
-		h.ensureInPath(binDir)
+		h.ensureInPath(binDir, result)
 
-		// Add to config with special marker for aliases
-		cfg.AddEntry(aliasName, "alias:"+command, batPath)
+		// Add to config
+		entry := cfg.AddAlias(aliasName, command, shims[0].path)
+		recordShims(entry, shims, opts)
+		recordAlias(entry, opts)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 
 	case "remove":
 		// Check if this alias was installed by LNB
 		entry, exists := cfg.GetEntry(aliasName)
 		if !exists {
-			return fmt.Errorf("alias '%s' was not installed by LNB", aliasName)
+			return nil, Errorf(ErrNotInstalled, "alias '%s' was not installed by LNB", aliasName)
 		}
 
 		// Verify the target path matches what we expect
-		if entry.TargetPath != batPath {
-			return fmt.Errorf("alias '%s' target path mismatch: expected %s, found %s", aliasName, batPath, entry.TargetPath)
+		expected := shimTarget(entry, ".bat")
+		result.TargetPath = expected
+		if entry.TargetPath != expected {
+			return nil, Errorf(ErrTargetMismatch, "alias '%s' target path mismatch: expected %s, found %s", aliasName, expected, entry.TargetPath)
 		}
 
-		err := os.Remove(batPath)
-		if err != nil {
-			return fmt.Errorf("failed to remove alias: %v", err)
+		if err := removeTargets(entry, result); err != nil {
+			return nil, fmt.Errorf("failed to remove alias: %v", err)
 		}
-		fmt.Printf("Removed alias: %s\n", aliasName)
 
 		// Remove from config
 		cfg.RemoveEntry(aliasName)
 		if err := cfg.Save(); err != nil {
-			fmt.Printf("Warning: failed to update config: %v\n", err)
+			result.warnf("failed to update config: %v", err)
 		}
 	}
-	return nil
+	return result, nil
 }
 
-// convertRelativePaths converts relative paths in command to absolute paths (Windows version)
-func (h *windowsHandler) convertRelativePaths(command string) string {
-	args := parseShellArgsWindows(command)
-
-	for i, arg := range args {
-		// Remove quotes temporarily to check the path, but preserve them in the final result
-		unquotedArg := arg
-		hasQuotes := false
-		var quoteChar string
-
-		if (strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`)) ||
-			(strings.HasPrefix(arg, `'`) && strings.HasSuffix(arg, `'`)) {
-			hasQuotes = true
-			quoteChar = string(arg[0])
-			unquotedArg = arg[1 : len(arg)-1]
-		}
+// Files renders every launcher of an entry, the target first
+func (h *windowsHandler) Files(entry *config.LnbEntry) []File {
+	dir := filepath.Dir(entry.TargetPath)
+	opts := EntryOptions(entry)
 
-		// Check if this looks like a relative path
-		// On Windows, relative paths might start with .\ or ..\ or be just filenames
-		if strings.HasPrefix(unquotedArg, ".\\") || strings.HasPrefix(unquotedArg, "..\\") ||
-			strings.HasPrefix(unquotedArg, "./") || strings.HasPrefix(unquotedArg, "../") ||
-			(strings.Contains(unquotedArg, ".") && !strings.Contains(unquotedArg, ":") && !strings.Contains(unquotedArg, "://")) {
-			if absPath, err := filepath.Abs(unquotedArg); err == nil {
-				// Verify the file exists before converting
-				if _, err := os.Stat(absPath); err == nil {
-					// Always preserve quotes if they were there, or add them if path contains spaces
-					if hasQuotes {
-						args[i] = quoteChar + absPath + quoteChar
-					} else if strings.Contains(absPath, " ") {
-						args[i] = `"` + absPath + `"`
-					} else {
-						args[i] = absPath
-					}
-				}
+	var shims []shimFile
+	if entry.Command != nil {
+		command := aliasCommand(entry)
+		shims = shimFiles(dir, entry.Name, ".bat", opts, func() string { return batchScript(command) }, command)
+	} else {
+		shims, _ = binaryShims(dir, entry.Name, entry.SourcePath, opts)
+	}
+
+	files := make([]File, len(shims))
+	for i, shim := range shims {
+		files[i] = File{Path: shim.path, Content: shim.render()}
+	}
+	return files
+}
+
+// binaryShims lists the launchers written for the binary at absPath and
+// reports whether it lives inside a WSL distribution, in which case it is
+// started through wsl.exe
+func binaryShims(dir, name, absPath string, opts Options) ([]shimFile, bool) {
+	command := config.NewCommand(absPath)
+	renderCmd := func() string { return cmdBinaryScript(absPath) }
+
+	distro, linuxPath, inWSL := wsl.ParseUNC(absPath)
+	if inWSL {
+		command = &config.Command{Argv: wsl.Command(distro, linuxPath)}
+		renderCmd = func() string { return batchScript(command) }
+	}
+	return shimFiles(dir, name, ".cmd", opts, renderCmd, command), inWSL
+}
+
+// shimFile is a launcher to write and how to render its contents
+type shimFile struct {
+	path   string
+	render func() string
+}
+
+// shimFiles lists the launchers written for an entry, the one recorded as the
+// entry's target first. cmdExt is .cmd for binaries and .bat for aliases, and
+// renderCmd renders that file; command is what the other launchers run.
+func shimFiles(dir, name, cmdExt string, opts Options, renderCmd func() string, command *config.Command) []shimFile {
+	cmdShim := shimFile{filepath.Join(dir, name+cmdExt), renderCmd}
+	ps1Shim := shimFile{filepath.Join(dir, name+".ps1"), func() string { return powershellScript(command) }}
+
+	var shims []shimFile
+	switch opts.Shim {
+	case config.ShimPS1:
+		shims = []shimFile{ps1Shim}
+	case config.ShimBoth:
+		shims = []shimFile{cmdShim, ps1Shim}
+	default:
+		shims = []shimFile{cmdShim}
+	}
+	if opts.Posix {
+		shims = append(shims, shimFile{filepath.Join(dir, name), func() string { return posixScript(command) }})
+	}
+	return shims
+}
+
+// shimTarget returns where an existing entry's target launcher should be
+func shimTarget(entry *config.LnbEntry, cmdExt string) string {
+	if entry.Shim == config.ShimPS1 {
+		return entryTarget(entry, entry.Name+".ps1")
+	}
+	return entryTarget(entry, entry.Name+cmdExt)
+}
+
+// writeShims writes each launcher. Nothing is left behind if one of them
+// cannot be written.
+func writeShims(shims []shimFile) error {
+	for i, shim := range shims {
+		if err := fsops.WriteFile(shim.path, []byte(shim.render()), 0755); err != nil {
+			for _, written := range shims[:i] {
+				fsops.Remove(written.path)
 			}
+			return err
 		}
 	}
+	return nil
+}
 
-	return reconstructCommandWindows(args)
+// noteShims tells the user about limits of the chosen shim style
+func noteShims(result *Result, style config.ShimStyle) {
+	if style == config.ShimPS1 {
+		result.notef("'%s' only has a PowerShell launcher; cmd.exe and other programs will not find it. Use --shim both to get a .cmd/.bat as well.", result.Name)
+	}
 }
 
-// validateCommand does basic validation since path resolution is handled by main handler
-func (h *windowsHandler) validateCommand(command string) error {
-	if strings.TrimSpace(command) == "" {
-		return fmt.Errorf("empty command")
+// recordShims stores the shim options and any launchers beyond the target
+func recordShims(entry *config.LnbEntry, shims []shimFile, opts Options) {
+	if opts.Shim != "" && opts.Shim != config.ShimCmd {
+		entry.Shim = opts.Shim
+	}
+	entry.PosixShim = opts.Posix
+	entry.ExtraTargets = nil
+	for _, shim := range shims[1:] {
+		entry.ExtraTargets = append(entry.ExtraTargets, shim.path)
 	}
-	return nil
 }
 
 // isInUserPath checks if the given directory is in the user's PATH
 func (h *windowsHandler) isInUserPath(dir string) bool {
-	cmd := exec.Command("powershell", "-Command",
-		"[Environment]::GetEnvironmentVariable('Path', 'User') -split ';' | Where-Object { $_ -eq '"+dir+"' }")
-	output, err := cmd.Output()
-	return err == nil && len(strings.TrimSpace(string(output))) > 0
+	value, _, err := winpath.ReadUser()
+	return err == nil && winpath.Contains(value, dir, os.Getenv)
 }
 
 // addToUserPath adds a directory to the user's PATH environment variable
 func (h *windowsHandler) addToUserPath(dir string) error {
-	// Use PowerShell to add to user PATH
-	cmd := exec.Command("powershell", "-Command",
-		"$currentPath = [Environment]::GetEnvironmentVariable('Path', 'User'); "+
-			"if ($currentPath -notlike '*"+dir+"*') { "+
-			"$newPath = if ($currentPath) { $currentPath + ';' + '"+dir+"' } else { '"+dir+"' }; "+
-			"[Environment]::SetEnvironmentVariable('Path', $newPath, 'User'); "+
-			"Write-Host 'Added to PATH' } else { Write-Host 'Already in PATH' }")
-
-	output, err := cmd.CombinedOutput()
+	value, expandable, err := winpath.ReadUser()
 	if err != nil {
-		return fmt.Errorf("failed to add directory to PATH: %v\nOutput: %s", err, string(output))
+		return err
 	}
 
-	fmt.Printf("🔧 %s\n", strings.TrimSpace(string(output)))
-	return nil
+	updated, changed := winpath.Add(value, dir, os.Getenv)
+	if !changed {
+		return nil
+	}
+	return fsops.Change(fsops.Op{Action: fsops.OpAddPath, Path: dir}, func() error {
+		return winpath.WriteUser(updated, expandable)
+	})
 }
 
 // ensureInPath ensures the bin directory is in the user's PATH
-func (h *windowsHandler) ensureInPath(binDir string) {
-	if !h.isInUserPath(binDir) {
-		fmt.Printf("📍 Adding %s to your PATH...\n", binDir)
-		if err := h.addToUserPath(binDir); err != nil {
-			fmt.Printf("⚠️  Failed to automatically add to PATH: %v\n", err)
-			fmt.Printf("⚠️  Please manually add %s to your PATH environment variable\n", binDir)
-		} else {
-			fmt.Println("✅ Successfully added to PATH! Restart your terminal to use the new PATH.")
-		}
-	} else {
-		fmt.Printf("✅ %s is already in your PATH\n", binDir)
+func (h *windowsHandler) ensureInPath(binDir string, result *Result) {
+	if h.isInUserPath(binDir) {
+		return
+	}
+	if err := h.addToUserPath(binDir); err != nil {
+		result.warnf("Failed to automatically add %s to PATH: %v", binDir, err)
+		result.warnf("Please manually add %s to your PATH environment variable", binDir)
+		return
 	}
+	result.notef("Added %s to your PATH. Restart your terminal to use the new PATH.", binDir)
 }
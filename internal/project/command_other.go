@@ -0,0 +1,14 @@
+//go:build !windows
+
+package project
+
+import "os/exec"
+
+// Command returns a command that runs a project alias's command line in dir
+// with args appended, the way an alias wrapper would: through bash, with the
+// arguments as "$@"
+func Command(line, dir string, args []string) *exec.Cmd {
+	cmd := exec.Command("bash", append([]string{"-c", line + ` "$@"`, "lnb"}, args...)...)
+	cmd.Dir = dir
+	return cmd
+}
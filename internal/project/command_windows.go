@@ -0,0 +1,21 @@
+package project
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Command returns a command that runs a project alias's command line in dir
+// with args appended, the way a .bat wrapper would: through cmd.exe, with the
+// arguments quoted as Windows programs expect
+func Command(line, dir string, args []string) *exec.Cmd {
+	words := []string{line}
+	for _, arg := range args {
+		words = append(words, syscall.EscapeArg(arg))
+	}
+	cmd := exec.Command("cmd.exe")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd.exe /d /s /c "` + strings.Join(words, " ") + `"`}
+	cmd.Dir = dir
+	return cmd
+}
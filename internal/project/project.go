@@ -0,0 +1,309 @@
+// Package project reads and writes .lnb.yaml files, which define aliases that
+// apply in one project directory and the directories below it.
+//
+// Only a small part of YAML is understood: comments, and an "aliases" mapping
+// of names to command lines, each written plain, 'single-quoted' or
+// "double-quoted". Other top-level keys are left alone. Changes are made line
+// by line, so comments and formatting survive.
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"lnb/internal/fsops"
+)
+
+// FileName is the name of a project file
+const FileName = ".lnb.yaml"
+
+// header starts a project file lnb creates
+const header = "# Project aliases for lnb. Each one runs from this directory when its name\n# is used here or in a directory below; see 'lnb alias --local'.\n"
+
+// File is a parsed project file
+type File struct {
+	Path    string
+	lines   []string
+	aliases []alias
+	block   int // index of the "aliases:" line, -1 if there is none
+}
+
+// alias is one name: command line pair and where it was read from
+type alias struct {
+	name    string
+	command string
+	line    int
+}
+
+// Load reads the project file at path. A missing file is an empty File that
+// Save creates.
+func Load(path string) (*File, error) {
+	data, err := fsops.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &File{Path: path, block: -1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, string(data))
+}
+
+// Parse reads the contents of a project file
+func Parse(path, content string) (*File, error) {
+	f := &File{Path: path, block: -1}
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if content != "" {
+		f.lines = strings.Split(content, "\n")
+	}
+
+	inBlock := false
+	for i, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inBlock = trimmed == "aliases:" || strings.HasPrefix(trimmed, "aliases: #")
+			if inBlock {
+				f.block = i
+			}
+			continue
+		}
+		if !inBlock {
+			continue
+		}
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t\"'") {
+			return nil, fmt.Errorf("%s:%d: expected 'name: command'", path, i+1)
+		}
+		command, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, i+1, err)
+		}
+		if command == "" {
+			return nil, fmt.Errorf("%s:%d: '%s' has no command", path, i+1, name)
+		}
+		f.aliases = append(f.aliases, alias{name: name, command: command, line: i})
+	}
+	return f, nil
+}
+
+// Get returns the command line the file defines for name
+func (f *File) Get(name string) (string, bool) {
+	for _, a := range f.aliases {
+		if a.name == name {
+			return a.command, true
+		}
+	}
+	return "", false
+}
+
+// Names returns the names the file defines, in file order
+func (f *File) Names() []string {
+	names := make([]string, len(f.aliases))
+	for i, a := range f.aliases {
+		names[i] = a.name
+	}
+	return names
+}
+
+// Set defines name as command, replacing an existing definition in place
+func (f *File) Set(name, command string) {
+	line := "  " + name + ": " + formatValue(command)
+	for _, a := range f.aliases {
+		if a.name == name {
+			f.lines[a.line] = line
+			reparse(f)
+			return
+		}
+	}
+
+	switch {
+	case len(f.aliases) > 0:
+		f.insert(f.aliases[len(f.aliases)-1].line+1, line)
+	case f.block >= 0:
+		f.insert(f.block+1, line)
+	default:
+		if len(f.lines) == 0 {
+			f.lines = strings.Split(strings.TrimSuffix(header, "\n"), "\n")
+		}
+		f.lines = append(f.lines, "aliases:", line)
+	}
+	reparse(f)
+}
+
+// Remove deletes the definition of name and reports whether there was one
+func (f *File) Remove(name string) bool {
+	for _, a := range f.aliases {
+		if a.name == name {
+			f.lines = append(f.lines[:a.line], f.lines[a.line+1:]...)
+			reparse(f)
+			return true
+		}
+	}
+	return false
+}
+
+// Content returns the file as it would be written
+func (f *File) Content() string {
+	if len(f.lines) == 0 {
+		return ""
+	}
+	return strings.Join(f.lines, "\n") + "\n"
+}
+
+// Sum returns a fingerprint of the file's contents
+func (f *File) Sum() string {
+	sum := sha256.Sum256([]byte(f.Content()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Save writes the file
+func (f *File) Save() error {
+	return fsops.WriteFile(f.Path, []byte(f.Content()), 0644)
+}
+
+// insert adds a line before index i
+func (f *File) insert(i int, line string) {
+	f.lines = append(f.lines[:i], append([]string{line}, f.lines[i:]...)...)
+}
+
+// reparse refreshes the parsed aliases after the lines changed. The lines
+// were valid before and only lnb's own lines changed, so it cannot fail.
+func reparse(f *File) {
+	if parsed, err := Parse(f.Path, f.Content()); err == nil {
+		*f = *parsed
+	}
+}
+
+// parseValue reads a plain, single-quoted or double-quoted scalar, dropping a
+// trailing comment
+func parseValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				i++
+			case '"':
+				unquoted, err := strconv.Unquote(value[:i+1])
+				if err != nil {
+					return "", fmt.Errorf("invalid double-quoted string %s", value[:i+1])
+				}
+				return unquoted, checkRest(value[i+1:])
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted string")
+	case strings.HasPrefix(value, "'"):
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			if value[i] != '\'' {
+				b.WriteByte(value[i])
+				continue
+			}
+			if i+1 < len(value) && value[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), checkRest(value[i+1:])
+		}
+		return "", fmt.Errorf("unterminated single-quoted string")
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// checkRest accepts what may follow a quoted string: nothing or a comment
+func checkRest(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected '%s' after quoted string", rest)
+	}
+	return nil
+}
+
+// formatValue writes a command line plain when YAML reads it back unchanged,
+// and double-quoted otherwise
+func formatValue(command string) string {
+	plain := command != "" &&
+		command == strings.TrimSpace(command) &&
+		!strings.ContainsAny(command[:1], "-?:,[]{}#&*!|>'\"%@`") &&
+		!strings.Contains(command, ": ") &&
+		!strings.Contains(command, " #") &&
+		!strings.HasSuffix(command, ":")
+	for _, r := range command {
+		if r < ' ' || r == 0x7f {
+			plain = false
+		}
+	}
+	if plain {
+		return command
+	}
+	return strconv.Quote(command)
+}
+
+// Find looks for the nearest project file in dir or above it that defines
+// name. It returns nil if there is none.
+func Find(dir, name string) (*File, error) {
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := fsops.Stat(path); err == nil {
+			f, err := Load(path)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := f.Get(name); ok {
+				return f, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Nearest returns the path of the nearest project file in dir or above it
+func Nearest(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := fsops.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Root returns the directory a new project file for dir belongs in: the one
+// holding the nearest project file, else the nearest git work tree, else dir
+func Root(dir string) string {
+	if path, ok := Nearest(dir); ok {
+		return filepath.Dir(path)
+	}
+	for current := dir; ; {
+		if _, err := fsops.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
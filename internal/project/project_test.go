@@ -0,0 +1,106 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := `# team aliases
+other: value
+aliases:   
+  build: make -C backend   # the usual
+  test: "go test ./... | tee \"out.txt\""
+  deploy: 'echo ''it''s'' done'
+tools:
+  lint: not an alias
+`
+	f, err := Parse(".lnb.yaml", content)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]string{
+		"build":  "make -C backend",
+		"test":   `go test ./... | tee "out.txt"`,
+		"deploy": "echo 'it's' done",
+	}
+	for name, command := range want {
+		if got, ok := f.Get(name); !ok || got != command {
+			t.Errorf("Get(%q) = %q, %v, want %q", name, got, ok, command)
+		}
+	}
+	if _, ok := f.Get("lint"); ok {
+		t.Error("keys outside aliases should be ignored")
+	}
+
+	for _, bad := range []string{"aliases:\n  build\n", "aliases:\n  build: \"open\n", "aliases:\n  build: 'x' y\n", "aliases:\n  build:\n"} {
+		if _, err := Parse(".lnb.yaml", bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSetAndRemove(t *testing.T) {
+	f, err := Parse(".lnb.yaml", "# keep me\naliases:\n  build: make\n\nother: 1\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	f.Set("build", "make -C backend")
+	f.Set("lint", "golangci-lint run ./... # all")
+	f.Set("odd", " needs: quotes")
+	want := "# keep me\naliases:\n  build: make -C backend\n  lint: \"golangci-lint run ./... # all\"\n  odd: \" needs: quotes\"\n\nother: 1\n"
+	if got := f.Content(); got != want {
+		t.Errorf("Content() =\n%s\nwant\n%s", got, want)
+	}
+	for name, command := range map[string]string{"lint": "golangci-lint run ./... # all", "odd": " needs: quotes"} {
+		if got, _ := f.Get(name); got != command {
+			t.Errorf("Get(%q) after Set = %q, want %q", name, got, command)
+		}
+	}
+
+	if !f.Remove("lint") || f.Remove("lint") {
+		t.Error("Remove should report whether the alias was defined")
+	}
+	if got := f.Names(); !reflect.DeepEqual(got, []string{"build", "odd"}) {
+		t.Errorf("Names() = %v", got)
+	}
+
+	empty, _ := Parse(".lnb.yaml", "")
+	empty.Set("build", "make")
+	if got := empty.Content(); got != header+"aliases:\n  build: make\n" {
+		t.Errorf("new file =\n%s", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "backend", "cmd")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	os.WriteFile(filepath.Join(root, FileName), []byte("aliases:\n  build: make\n  test: go test\n"), 0644)
+	os.WriteFile(filepath.Join(root, "backend", FileName), []byte("aliases:\n  build: make -C backend\n"), 0644)
+
+	f, err := Find(sub, "build")
+	if err != nil || f == nil || f.Path != filepath.Join(root, "backend", FileName) {
+		t.Fatalf("Find(build) = %v, %v; want the backend file", f, err)
+	}
+	if f, _ = Find(sub, "test"); f == nil || f.Path != filepath.Join(root, FileName) {
+		t.Errorf("Find(test) should fall through to the project root")
+	}
+	if f, _ = Find(sub, "deploy"); f != nil {
+		t.Errorf("Find(deploy) = %s, want nothing", f.Path)
+	}
+
+	if got := Root(filepath.Join(root, "backend")); got != filepath.Join(root, "backend") {
+		t.Errorf("Root() = %s, want the directory of the nearest project file", got)
+	}
+	os.Remove(filepath.Join(root, "backend", FileName))
+	os.Remove(filepath.Join(root, FileName))
+	if got := Root(sub); got != root {
+		t.Errorf("Root() = %s, want the git work tree %s", got, root)
+	}
+}
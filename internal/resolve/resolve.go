@@ -0,0 +1,125 @@
+// Package resolve finds every executable a command name resolves to on PATH,
+// in the order a shell would try them.
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lnb/internal/winpath"
+)
+
+// Match is one executable a name resolves to
+type Match struct {
+	Path    string // full path of the executable
+	Dir     string // PATH entry it was found in
+	Managed bool   // Dir is the lnb bin directory
+	order   int    // position of Dir on PATH
+}
+
+// Resolution is the full lookup chain for a name. The first match is the one
+// that runs; every later match is shadowed by it.
+type Resolution struct {
+	Name     string
+	BinDir   string
+	Matches  []Match
+	binOrder int // position of BinDir on PATH, -1 if it is not on PATH
+}
+
+// Lookup resolves name against the directories in pathList. On Windows each
+// directory is tried with every extension in pathExt, as cmd.exe does.
+func Lookup(name, binDir, pathList, pathExt, goos string) *Resolution {
+	resolution := &Resolution{Name: name, BinDir: binDir, binOrder: -1}
+
+	exts := []string{""}
+	if goos == "windows" {
+		exts = nil
+		if filepath.Ext(name) != "" {
+			exts = append(exts, "")
+		}
+		for _, ext := range strings.Split(pathExt, ";") {
+			if ext != "" {
+				exts = append(exts, strings.ToLower(ext))
+			}
+		}
+		if pathExt == "" {
+			exts = append(exts, ".com", ".exe", ".bat", ".cmd")
+		}
+	}
+
+	seen := make(map[string]bool)
+	order := 0
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		key := normalize(dir, goos)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		managed := key == normalize(binDir, goos)
+		if managed {
+			resolution.binOrder = order
+		}
+
+		for _, ext := range exts {
+			candidate := filepath.Join(dir, name+ext)
+			if isExecutable(candidate, goos) {
+				resolution.Matches = append(resolution.Matches, Match{Path: candidate, Dir: dir, Managed: managed, order: order})
+			}
+		}
+		order++
+	}
+
+	return resolution
+}
+
+// BinDirInPath reports whether the bin directory is on PATH at all
+func (r *Resolution) BinDirInPath() bool {
+	return r.binOrder >= 0
+}
+
+// Shadows returns the executables a command in the bin directory takes
+// precedence over, because their directory comes later on PATH
+func (r *Resolution) Shadows() []string {
+	var paths []string
+	for _, match := range r.Matches {
+		if r.BinDirInPath() && match.order > r.binOrder {
+			paths = append(paths, match.Path)
+		}
+	}
+	return paths
+}
+
+// ShadowedBy returns the executables that take precedence over a command in
+// the bin directory, because their directory comes earlier on PATH. While the
+// bin directory is not on PATH, every match runs instead, so all are returned.
+func (r *Resolution) ShadowedBy() []string {
+	var paths []string
+	for _, match := range r.Matches {
+		if !r.BinDirInPath() || match.order < r.binOrder {
+			paths = append(paths, match.Path)
+		}
+	}
+	return paths
+}
+
+// normalize returns a comparable form of a PATH entry
+func normalize(dir, goos string) string {
+	if goos == "windows" {
+		return winpath.Normalize(dir, os.Getenv)
+	}
+	return filepath.Clean(dir)
+}
+
+// isExecutable reports whether path is a file the OS would run
+func isExecutable(path, goos string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return goos == "windows" || info.Mode()&0111 != 0
+}
@@ -0,0 +1,141 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixture creates dirs under a temporary directory with the given files, and
+// returns the root and a PATH listing the dirs in order
+func fixture(t *testing.T, dirs []string, files map[string]os.FileMode) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	var entries []string
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, filepath.Join(root, dir))
+	}
+	for path, mode := range files {
+		if err := os.WriteFile(filepath.Join(root, path), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, strings.Join(entries, string(os.PathListSeparator))
+}
+
+// relative returns paths relative to root, with forward slashes
+func relative(root string, paths []string) []string {
+	var rel []string
+	for _, path := range paths {
+		r, _ := filepath.Rel(root, path)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func TestLookupUnix(t *testing.T) {
+	root, pathList := fixture(t, []string{"first", "bin", "last", "first"}, map[string]os.FileMode{
+		"first/tool": 0755,
+		"bin/tool":   0755,
+		"last/tool":  0755,
+		"last/data":  0644,
+	})
+	binDir := filepath.Join(root, "bin")
+
+	tests := []struct {
+		name       string
+		pathList   string
+		matches    []string
+		shadows    []string
+		shadowedBy []string
+	}{
+		{"tool", pathList, []string{"first/tool", "bin/tool", "last/tool"}, []string{"last/tool"}, []string{"first/tool"}},
+		{"data", pathList, nil, nil, nil},
+		{"missing", pathList, nil, nil, nil},
+		// Without the bin dir on PATH every match runs instead of lnb's command
+		{"tool", filepath.Join(root, "last") + string(os.PathListSeparator) + filepath.Join(root, "first"), []string{"last/tool", "first/tool"}, nil, []string{"last/tool", "first/tool"}},
+	}
+
+	for _, tc := range tests {
+		resolution := Lookup(tc.name, binDir, tc.pathList, "", "linux")
+		var matches []string
+		for _, match := range resolution.Matches {
+			matches = append(matches, match.Path)
+			if match.Managed != (match.Dir == binDir) {
+				t.Errorf("%s: %s has Managed %v", tc.name, match.Path, match.Managed)
+			}
+		}
+		if got := relative(root, matches); !reflect.DeepEqual(got, tc.matches) {
+			t.Errorf("Lookup(%s) matches = %v, want %v", tc.name, got, tc.matches)
+		}
+		if got := relative(root, resolution.Shadows()); !reflect.DeepEqual(got, tc.shadows) {
+			t.Errorf("Lookup(%s).Shadows() = %v, want %v", tc.name, got, tc.shadows)
+		}
+		if got := relative(root, resolution.ShadowedBy()); !reflect.DeepEqual(got, tc.shadowedBy) {
+			t.Errorf("Lookup(%s).ShadowedBy() = %v, want %v", tc.name, got, tc.shadowedBy)
+		}
+	}
+}
+
+func TestLookupBinDirInPath(t *testing.T) {
+	root, pathList := fixture(t, []string{"a", "bin"}, nil)
+	if !Lookup("x", filepath.Join(root, "bin")+string(filepath.Separator), pathList, "", "linux").BinDirInPath() {
+		t.Error("a bin dir with a trailing separator should be found on PATH")
+	}
+	if Lookup("x", filepath.Join(root, "other"), pathList, "", "linux").BinDirInPath() {
+		t.Error("a bin dir missing from PATH was reported on it")
+	}
+	if !Lookup("x", strings.ToUpper(filepath.Join(root, "bin")), pathList, "", "windows").BinDirInPath() {
+		t.Error("PATH entries should compare case-insensitively on Windows")
+	}
+}
+
+func TestLookupWindows(t *testing.T) {
+	root, pathList := fixture(t, []string{"bin", "tools"}, map[string]os.FileMode{
+		"bin/tool.cmd":   0644,
+		"tools/tool.exe": 0644,
+		"tools/tool.cmd": 0644,
+		"tools/tool.ps1": 0644,
+		"tools/tool":     0644,
+	})
+	binDir := filepath.Join(root, "bin")
+
+	tests := []struct {
+		name    string
+		pathExt string
+		matches []string
+	}{
+		// Extensions are tried in PATHEXT order, in each directory in turn
+		{"tool", ".EXE;.CMD", []string{"bin/tool.cmd", "tools/tool.exe", "tools/tool.cmd"}},
+		{"tool", ".CMD;.EXE", []string{"bin/tool.cmd", "tools/tool.cmd", "tools/tool.exe"}},
+		{"tool", ".PS1;;", []string{"tools/tool.ps1"}},
+		// An empty PATHEXT falls back to what cmd.exe uses
+		{"tool", "", []string{"bin/tool.cmd", "tools/tool.exe", "tools/tool.cmd"}},
+		// A name with an extension is tried as it is first
+		{"tool.cmd", ".EXE", []string{"bin/tool.cmd", "tools/tool.cmd"}},
+	}
+
+	for _, tc := range tests {
+		resolution := Lookup(tc.name, binDir, pathList, tc.pathExt, "windows")
+		var matches []string
+		for _, match := range resolution.Matches {
+			matches = append(matches, match.Path)
+		}
+		if got := relative(root, matches); !reflect.DeepEqual(got, tc.matches) {
+			t.Errorf("Lookup(%s, PATHEXT=%q) matches = %v, want %v", tc.name, tc.pathExt, got, tc.matches)
+		}
+	}
+
+	resolution := Lookup("tool", binDir, pathList, ".EXE;.CMD", "windows")
+	if got := relative(root, resolution.Shadows()); !reflect.DeepEqual(got, []string{"tools/tool.exe", "tools/tool.cmd"}) {
+		t.Errorf("Shadows() = %v", got)
+	}
+	if got := resolution.ShadowedBy(); len(got) != 0 {
+		t.Errorf("ShadowedBy() = %v, want none", got)
+	}
+}
@@ -0,0 +1,205 @@
+// Package shellrc keeps the lnb bin directory on PATH for bash, zsh and fish
+// by managing a clearly marked block in each shell's startup file.
+package shellrc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"lnb/internal/fsops"
+)
+
+const (
+	blockStart = "# >>> lnb PATH >>>"
+	blockEnd   = "# <<< lnb PATH <<<"
+)
+
+// Shell is a shell whose startup file lnb can edit
+type Shell struct {
+	Name   string // bash, zsh or fish
+	RCFile string // startup file the PATH block goes into
+}
+
+// Status is what lnb knows about the bin directory for one shell
+type Status struct {
+	Shell
+	HasBlock  bool // the rc file contains the lnb block
+	Mentioned bool // the rc file adds the bin directory to PATH some other way
+}
+
+// OnPath reports whether a new shell of this kind will have the bin directory on PATH
+func (s Status) OnPath() bool {
+	return s.HasBlock || s.Mentioned
+}
+
+// Detect returns the shells the user has: the login shell from $SHELL, any of
+// bash, zsh and fish installed on PATH, and any whose rc file already exists
+func Detect(home string) []Shell {
+	loginShell := filepath.Base(os.Getenv("SHELL"))
+
+	var shells []Shell
+	for _, name := range []string{"bash", "zsh", "fish"} {
+		shell := Shell{Name: name, RCFile: rcFile(name, home)}
+		_, lookErr := exec.LookPath(name)
+		_, statErr := fsops.Stat(shell.RCFile)
+		if name == loginShell || lookErr == nil || statErr == nil {
+			shells = append(shells, shell)
+		}
+	}
+	return shells
+}
+
+// rcFile returns the startup file for a shell, honouring ZDOTDIR and XDG_CONFIG_HOME
+func rcFile(name, home string) string {
+	switch name {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc")
+		}
+		return filepath.Join(home, ".zshrc")
+	case "fish":
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "config.fish")
+	default:
+		// Terminal windows on macOS start login shells, which read .bash_profile
+		if runtime.GOOS == "darwin" {
+			return filepath.Join(home, ".bash_profile")
+		}
+		return filepath.Join(home, ".bashrc")
+	}
+}
+
+// Check reports whether each shell's rc file puts binDir on PATH
+func Check(shells []Shell, binDir, home string) ([]Status, error) {
+	statuses := make([]Status, 0, len(shells))
+	for _, shell := range shells {
+		content, err := readFile(shell.RCFile)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, Status{
+			Shell:     shell,
+			HasBlock:  strings.Contains(content, blockStart),
+			Mentioned: mentionsDir(stripBlock(content), binDir, home),
+		})
+	}
+	return statuses, nil
+}
+
+// Add writes the lnb block to the shell's rc file, replacing an existing block
+func Add(shell Shell, binDir string) error {
+	content, err := readFile(shell.RCFile)
+	if err != nil {
+		return err
+	}
+
+	updated := stripBlock(content)
+	if updated != "" && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	updated += Block(shell.Name, binDir)
+
+	if err := fsops.MkdirAll(filepath.Dir(shell.RCFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(shell.RCFile), err)
+	}
+	return writeFile(shell.RCFile, updated)
+}
+
+// Remove deletes the lnb block from the shell's rc file. It reports whether
+// there was a block to remove.
+func Remove(shell Shell) (bool, error) {
+	content, err := readFile(shell.RCFile)
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(content, blockStart) {
+		return false, nil
+	}
+	return true, writeFile(shell.RCFile, stripBlock(content))
+}
+
+// Block renders the marked PATH block for a shell
+func Block(shell, binDir string) string {
+	var line string
+	if shell == "fish" {
+		line = fmt.Sprintf("contains -- %s $PATH; or set -gx PATH %s $PATH", fishQuote(binDir), fishQuote(binDir))
+	} else {
+		line = fmt.Sprintf(`case ":$PATH:" in *:%s:*) ;; *) export PATH=%s:"$PATH" ;; esac`, shQuote(binDir), shQuote(binDir))
+	}
+
+	return blockStart + "\n" +
+		"# Added by lnb so commands it creates are found. Remove with 'lnb self uninstall'.\n" +
+		line + "\n" +
+		blockEnd + "\n"
+}
+
+// stripBlock returns content without the lnb block
+func stripBlock(content string) string {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return content
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return content
+	}
+	end += start + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:]
+}
+
+// mentionsDir reports whether content has a PATH line naming binDir, written
+// either literally or relative to $HOME or ~
+func mentionsDir(content, binDir, home string) bool {
+	forms := []string{regexp.QuoteMeta(binDir)}
+	if rel, err := filepath.Rel(home, binDir); err == nil && !strings.HasPrefix(rel, "..") {
+		rel = regexp.QuoteMeta(filepath.ToSlash(rel))
+		forms = append(forms, `\$HOME/`+rel, `\$\{HOME\}/`+rel, `~/`+rel)
+	}
+	pattern := regexp.MustCompile(`(?m)^[^#\n]*PATH[^#\n]*(` + strings.Join(forms, "|") + `)/?([:"'\s;]|$)`)
+	return pattern.MatchString(content)
+}
+
+// shQuote quotes s for a POSIX shell
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// readFile returns the content of path, or "" if it does not exist
+func readFile(path string) (string, error) {
+	data, err := fsops.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return string(data), nil
+}
+
+// writeFile replaces path's content, keeping its permissions
+func writeFile(path, content string) error {
+	mode := os.FileMode(0644)
+	if info, err := fsops.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := fsops.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
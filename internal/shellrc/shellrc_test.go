@@ -0,0 +1,78 @@
+package shellrc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddIsIdempotentAndRemoveRestores(t *testing.T) {
+	home := t.TempDir()
+	binDir := filepath.Join(home, ".local", "bin")
+	original := "export EDITOR=vim\n"
+
+	for _, name := range []string{"bash", "zsh", "fish"} {
+		t.Run(name, func(t *testing.T) {
+			shell := Shell{Name: name, RCFile: filepath.Join(home, name+"rc")}
+			if err := os.WriteFile(shell.RCFile, []byte(original), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if err := Add(shell, binDir); err != nil {
+					t.Fatalf("Add: %v", err)
+				}
+			}
+
+			data, _ := os.ReadFile(shell.RCFile)
+			if count := strings.Count(string(data), blockStart); count != 1 {
+				t.Errorf("Expected exactly one lnb block, found %d:\n%s", count, data)
+			}
+			if !strings.HasPrefix(string(data), original) {
+				t.Errorf("Existing content was changed:\n%s", data)
+			}
+
+			statuses, err := Check([]Shell{shell}, binDir, home)
+			if err != nil || !statuses[0].HasBlock || !statuses[0].OnPath() {
+				t.Errorf("Expected block to be detected, got %+v (err %v)", statuses, err)
+			}
+
+			removed, err := Remove(shell)
+			if err != nil || !removed {
+				t.Fatalf("Remove: removed=%v err=%v", removed, err)
+			}
+			data, _ = os.ReadFile(shell.RCFile)
+			if string(data) != original {
+				t.Errorf("Expected original content after Remove, got:\n%s", data)
+			}
+			if info, _ := os.Stat(shell.RCFile); info.Mode().Perm() != 0600 {
+				t.Errorf("Expected permissions to be kept, got %v", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestCheckFindsExistingPathLines(t *testing.T) {
+	home := "/home/me"
+	binDir := "/home/me/.local/bin"
+
+	testCases := []struct {
+		content string
+		want    bool
+	}{
+		{`export PATH="$HOME/.local/bin:$PATH"`, true},
+		{`export PATH=~/.local/bin:$PATH`, true},
+		{`PATH="/home/me/.local/bin:${PATH}"`, true},
+		{`set -gx PATH $HOME/.local/bin $PATH`, true},
+		{`# export PATH="$HOME/.local/bin:$PATH"`, false},
+		{`export PATH="$HOME/.local/binaries:$PATH"`, false},
+		{`alias ll='ls -la ~/.local/bin'`, false},
+	}
+
+	for _, tc := range testCases {
+		if got := mentionsDir(tc.content, binDir, home); got != tc.want {
+			t.Errorf("mentionsDir(%q) = %v, want %v", tc.content, got, tc.want)
+		}
+	}
+}
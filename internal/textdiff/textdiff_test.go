@@ -0,0 +1,45 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"change",
+			"#!/bin/sh\nexec echo one \"$@\"\n",
+			"#!/bin/sh\nexec echo two \"$@\"\n",
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n #!/bin/sh\n-exec echo one \"$@\"\n+exec echo two \"$@\"\n",
+		},
+		{
+			"added to empty",
+			"",
+			"x\n",
+			"--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\nX\n3\n4\n5\n6\n7\n8\n9\n10\n11\nY\n",
+			"--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+Y\n",
+		},
+		{
+			"nearby changes share a hunk",
+			"1\n2\n3\n4\n5\n6\n",
+			"1\nX\n3\n4\n5\nY\n",
+			"--- old\n+++ new\n@@ -1,6 +1,6 @@\n 1\n-2\n+X\n 3\n 4\n 5\n-6\n+Y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package winpath
+
+import "strings"
+
+// Drive mount points used by the POSIX environments that run on Windows
+const (
+	MountMSYS   = ""          // MSYS2 and Git Bash: C:\ is /c/
+	MountCygwin = "/cygdrive" // Cygwin: C:\ is /cygdrive/c/
+	MountWSL    = "/mnt"      // WSL: C:\ is /mnt/c/
+)
+
+// ToPosix converts an absolute Windows path such as C:\Tools\x.exe to the
+// form a POSIX shell on Windows uses, with the drive letter under mount:
+// /c/Tools/x.exe for MountMSYS. UNC paths become //server/share/... . It
+// reports false, and returns path unchanged, for anything else.
+func ToPosix(path, mount string) (string, bool) {
+	if isDrivePath(path) {
+		rest := strings.ReplaceAll(path[2:], `\`, "/")
+		if rest == "" {
+			rest = "/"
+		}
+		return mount + "/" + strings.ToLower(path[:1]) + rest, true
+	}
+	if strings.HasPrefix(path, `\\`) && len(path) > 2 {
+		return strings.ReplaceAll(path, `\`, "/"), true
+	}
+	return path, false
+}
+
+// FromPosix converts a path in any of the /c/..., /cygdrive/c/... or
+// /mnt/c/... forms back to C:\... . It reports false, and returns path
+// unchanged, for paths that are not on a drive.
+func FromPosix(path string) (string, bool) {
+	rest := path
+	for _, mount := range []string{MountCygwin, MountWSL} {
+		if trimmed, ok := strings.CutPrefix(path, mount+"/"); ok {
+			rest = "/" + trimmed
+			break
+		}
+	}
+
+	if len(rest) < 2 || rest[0] != '/' || !isLetter(rest[1]) || (len(rest) > 2 && rest[2] != '/') {
+		return path, false
+	}
+	converted := strings.ToUpper(rest[1:2]) + `:` + strings.ReplaceAll(rest[2:], "/", `\`)
+	if len(converted) == 2 {
+		converted += `\`
+	}
+	return converted, true
+}
+
+// isDrivePath reports whether path starts with a drive letter and a separator
+func isDrivePath(path string) bool {
+	return len(path) >= 3 && isLetter(path[0]) && path[1] == ':' && (path[2] == '\\' || path[2] == '/')
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,61 @@
+package winpath
+
+import "testing"
+
+func TestToPosix(t *testing.T) {
+	testCases := []struct {
+		path  string
+		mount string
+		want  string
+		ok    bool
+	}{
+		{`C:\Tools\rg.exe`, MountMSYS, `/c/Tools/rg.exe`, true},
+		{`C:\Program Files\Git`, MountWSL, `/mnt/c/Program Files/Git`, true},
+		{`d:/work/app`, MountCygwin, `/cygdrive/d/work/app`, true},
+		{`C:\`, MountMSYS, `/c/`, true},
+		{`\\server\share\tool.exe`, MountMSYS, `//server/share/tool.exe`, true},
+		{`tools\rg.exe`, MountMSYS, `tools\rg.exe`, false},
+		{`C:relative`, MountMSYS, `C:relative`, false},
+		{`rg`, MountMSYS, `rg`, false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := ToPosix(tc.path, tc.mount)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("ToPosix(%q, %q) = %q, %v; want %q, %v", tc.path, tc.mount, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestFromPosix(t *testing.T) {
+	testCases := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{`/c/Tools/rg.exe`, `C:\Tools\rg.exe`, true},
+		{`/mnt/d/work/app`, `D:\work\app`, true},
+		{`/cygdrive/c/Program Files/Git`, `C:\Program Files\Git`, true},
+		{`/c`, `C:\`, true},
+		{`/usr/bin/env`, `/usr/bin/env`, false},
+		{`/mnt/data`, `/mnt/data`, false},
+		{`rg`, `rg`, false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := FromPosix(tc.path)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("FromPosix(%q) = %q, %v; want %q, %v", tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	// Converting there and back is lossless apart from the drive letter case
+	for _, path := range []string{`C:\Tools\rg.exe`, `E:\a b\c`} {
+		for _, mount := range []string{MountMSYS, MountCygwin, MountWSL} {
+			posix, _ := ToPosix(path, mount)
+			if back, _ := FromPosix(posix); back != path {
+				t.Errorf("FromPosix(ToPosix(%q, %q)) = %q", path, mount, back)
+			}
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package winpath
+
+import "errors"
+
+var errNotWindows = errors.New("the user PATH registry value only exists on Windows")
+
+// ReadUser returns the user PATH from HKCU\Environment; only available on Windows
+func ReadUser() (string, bool, error) {
+	return "", false, errNotWindows
+}
+
+// WriteUser stores the user PATH in HKCU\Environment; only available on Windows
+func WriteUser(value string, expandable bool) error {
+	return errNotWindows
+}
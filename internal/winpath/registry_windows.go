@@ -0,0 +1,92 @@
+//go:build windows
+
+package winpath
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32             = syscall.NewLazyDLL("advapi32.dll")
+	user32               = syscall.NewLazyDLL("user32.dll")
+	procRegSetValueExW   = advapi32.NewProc("RegSetValueExW")
+	procSendMessageTimeW = user32.NewProc("SendMessageTimeoutW")
+)
+
+const (
+	hwndBroadcast    = 0xffff
+	wmSettingChange  = 0x001A
+	smtoAbortIfHung  = 0x0002
+	broadcastTimeout = 5000
+)
+
+// ReadUser returns the user PATH from HKCU\Environment, unexpanded, and
+// whether it is stored as REG_EXPAND_SZ
+func ReadUser() (string, bool, error) {
+	key, err := openEnvironment(syscall.KEY_QUERY_VALUE)
+	if err != nil {
+		return "", false, err
+	}
+	defer syscall.RegCloseKey(key)
+
+	name, _ := syscall.UTF16PtrFromString("Path")
+	var valueType, size uint32
+	err = syscall.RegQueryValueEx(key, name, nil, &valueType, nil, &size)
+	if err == syscall.ERROR_FILE_NOT_FOUND {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read user PATH: %v", err)
+	}
+
+	buf := make([]uint16, size/2+1)
+	if err := syscall.RegQueryValueEx(key, name, nil, &valueType, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+		return "", false, fmt.Errorf("failed to read user PATH: %v", err)
+	}
+	return syscall.UTF16ToString(buf), valueType == syscall.REG_EXPAND_SZ, nil
+}
+
+// WriteUser stores value as the user PATH and tells running programs that
+// the environment changed, so new terminals pick it up
+func WriteUser(value string, expandable bool) error {
+	key, err := openEnvironment(syscall.KEY_SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	valueType := uint32(syscall.REG_SZ)
+	if expandable {
+		valueType = syscall.REG_EXPAND_SZ
+	}
+
+	name, _ := syscall.UTF16PtrFromString("Path")
+	data, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return fmt.Errorf("invalid PATH value: %v", err)
+	}
+	ret, _, _ := procRegSetValueExW.Call(
+		uintptr(key), uintptr(unsafe.Pointer(name)), 0, uintptr(valueType),
+		uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)*2))
+	if ret != 0 {
+		return fmt.Errorf("failed to write user PATH: %v", syscall.Errno(ret))
+	}
+
+	environment, _ := syscall.UTF16PtrFromString("Environment")
+	var result uintptr
+	procSendMessageTimeW.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(environment)),
+		smtoAbortIfHung, broadcastTimeout, uintptr(unsafe.Pointer(&result)))
+	return nil
+}
+
+// openEnvironment opens HKCU\Environment with the given access
+func openEnvironment(access uint32) (syscall.Handle, error) {
+	var key syscall.Handle
+	path, _ := syscall.UTF16PtrFromString("Environment")
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_CURRENT_USER, path, 0, access, &key); err != nil {
+		return 0, fmt.Errorf("failed to open HKCU\\Environment: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,127 @@
+// Package winpath edits a Windows PATH value as a list of directories instead
+// of as a string, and translates paths to the forms POSIX shells on Windows
+// use. The parsing and editing here is plain string work that runs on any OS;
+// only registry_windows.go talks to Windows.
+package winpath
+
+import (
+	"strings"
+)
+
+// Split returns the entries of a PATH value, dropping empty ones
+func Split(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Join builds a PATH value from entries
+func Join(entries []string) string {
+	return strings.Join(entries, ";")
+}
+
+// Normalize returns the form of a PATH entry used for comparisons: quotes and
+// surrounding spaces removed, %VAR% references expanded with getenv, forward
+// slashes turned into backslashes, trailing backslashes dropped and letters
+// lowercased, since Windows paths are case-insensitive
+func Normalize(entry string, getenv func(string) string) string {
+	entry = strings.TrimSpace(entry)
+	entry = strings.Trim(entry, `"`)
+	entry = expand(entry, getenv)
+	entry = strings.ReplaceAll(entry, "/", `\`)
+
+	// Keep the backslash of a drive root: C:\ is not the same as C:
+	for len(entry) > 1 && strings.HasSuffix(entry, `\`) && !(len(entry) == 3 && entry[1] == ':') {
+		entry = entry[:len(entry)-1]
+	}
+	return strings.ToLower(entry)
+}
+
+// Contains reports whether value has an entry equivalent to dir
+func Contains(value, dir string, getenv func(string) string) bool {
+	return index(Split(value), dir, getenv) >= 0
+}
+
+// Add appends dir to value unless an equivalent entry is already there. The
+// result is also deduplicated. It reports whether value changed.
+func Add(value, dir string, getenv func(string) string) (string, bool) {
+	entries := Split(Dedupe(value, getenv))
+	if index(entries, dir, getenv) < 0 {
+		entries = append(entries, dir)
+	}
+	updated := Join(entries)
+	return updated, updated != value
+}
+
+// Remove drops every entry equivalent to dir from value. It reports whether value changed.
+func Remove(value, dir string, getenv func(string) string) (string, bool) {
+	want := Normalize(dir, getenv)
+	var kept []string
+	for _, entry := range Split(value) {
+		if Normalize(entry, getenv) != want {
+			kept = append(kept, entry)
+		}
+	}
+	updated := Join(kept)
+	return updated, updated != value
+}
+
+// Dedupe keeps the first of every group of equivalent entries, as spelled in value
+func Dedupe(value string, getenv func(string) string) string {
+	seen := make(map[string]bool)
+	var kept []string
+	for _, entry := range Split(value) {
+		key := Normalize(entry, getenv)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, entry)
+	}
+	return Join(kept)
+}
+
+// index returns the position of the first entry equivalent to dir, or -1
+func index(entries []string, dir string, getenv func(string) string) int {
+	want := Normalize(dir, getenv)
+	for i, entry := range entries {
+		if Normalize(entry, getenv) == want {
+			return i
+		}
+	}
+	return -1
+}
+
+// expand replaces %VAR% references the way cmd.exe does: names are
+// case-insensitive and unknown variables are left untouched
+func expand(s string, getenv func(string) string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+
+		name := s[start+1 : end]
+		if value := getenv(name); name != "" && value != "" {
+			b.WriteString(s[:start])
+			b.WriteString(value)
+			s = s[end+1:]
+		} else {
+			// Not a variable; keep the first % and look for the next reference from the second
+			b.WriteString(s[:end])
+			s = s[end:]
+		}
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package winpath
+
+import (
+	"strings"
+	"testing"
+)
+
+// testEnv looks variables up case-insensitively, like Windows does
+func testEnv(name string) string {
+	return map[string]string{
+		"userprofile":  `C:\Users\O'Brien`,
+		"localappdata": `C:\Users\O'Brien\AppData\Local`,
+	}[strings.ToLower(name)]
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		entry string
+		want  string
+	}{
+		{`C:\Tools\`, `c:\tools`},
+		{`c:/tools//`, `c:\tools`},
+		{` "C:\Program Files\Git\cmd" `, `c:\program files\git\cmd`},
+		{`%USERPROFILE%\bin`, `c:\users\o'brien\bin`},
+		{`%userprofile%\bin\`, `c:\users\o'brien\bin`},
+		{`%UNDEFINED%\bin`, `%undefined%\bin`},
+		{`100%\bin`, `100%\bin`},
+		{`C:\`, `c:\`},
+	}
+
+	for _, tc := range testCases {
+		if got := Normalize(tc.entry, testEnv); got != tc.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tc.entry, got, tc.want)
+		}
+	}
+}
+
+func TestContainsDoesNotMatchPrefixes(t *testing.T) {
+	value := `C:\Windows;C:\binaries;%LOCALAPPDATA%\Programs`
+
+	testCases := []struct {
+		dir  string
+		want bool
+	}{
+		{`C:\bin`, false},
+		{`C:\binaries`, true},
+		{`c:\BINARIES\`, true},
+		{`C:\Users\O'Brien\AppData\Local\Programs`, true},
+		{`C:\Win`, false},
+	}
+
+	for _, tc := range testCases {
+		if got := Contains(value, tc.dir, testEnv); got != tc.want {
+			t.Errorf("Contains(%q) = %v, want %v", tc.dir, got, tc.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		dir     string
+		want    string
+		changed bool
+	}{
+		{"empty", ``, `C:\Users\O'Brien\bin`, `C:\Users\O'Brien\bin`, true},
+		{"append", `C:\Windows`, `C:\Users\O'Brien\bin`, `C:\Windows;C:\Users\O'Brien\bin`, true},
+		{"already there via variable", `%USERPROFILE%\bin;C:\Windows`, `C:\Users\O'Brien\bin`, `%USERPROFILE%\bin;C:\Windows`, false},
+		{"already there with trailing slash", `C:\Users\O'Brien\bin\`, `C:\Users\O'Brien\bin`, `C:\Users\O'Brien\bin\`, false},
+		{"drops empty entries and duplicates", `C:\Windows;;c:\windows\;C:\Go\bin;`, `C:\Go\bin`, `C:\Windows;C:\Go\bin`, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed := Add(tc.value, tc.dir, testEnv)
+			if got != tc.want || changed != tc.changed {
+				t.Errorf("Add(%q, %q) = %q, %v; want %q, %v", tc.value, tc.dir, got, changed, tc.want, tc.changed)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	value := `C:\Windows;%USERPROFILE%\bin;C:\binaries;C:\Users\O'Brien\bin\`
+
+	got, changed := Remove(value, `C:\Users\O'Brien\bin`, testEnv)
+	if want := `C:\Windows;C:\binaries`; got != want || !changed {
+		t.Errorf("Remove = %q, %v; want %q, true", got, changed, want)
+	}
+
+	got, changed = Remove(`C:\Windows`, `C:\bin`, testEnv)
+	if got != `C:\Windows` || changed {
+		t.Errorf("Remove of missing dir = %q, %v; want unchanged", got, changed)
+	}
+}
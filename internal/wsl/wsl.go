@@ -0,0 +1,120 @@
+// Package wsl supports running executables across the WSL boundary: Windows
+// programs from inside a WSL distribution, and Linux programs in a
+// distribution from Windows. Everything here is plain string work apart from
+// Detect, so it can be tested without WSL.
+package wsl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// interopFile exists when the kernel can run Windows executables
+const interopFile = "/proc/sys/fs/binfmt_misc/WSLInterop"
+
+// Detect reports whether lnb is running inside WSL with Windows interop
+func Detect() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	_, err := os.Stat(interopFile)
+	return err == nil
+}
+
+var drivePath = regexp.MustCompile(`^/mnt/[a-zA-Z](/|$)`)
+
+// windowsExts are the extensions WSL interop hands to Windows
+var windowsExts = []string{".exe", ".bat", ".cmd", ".com"}
+
+// IsWindowsTarget reports whether path is a Windows program: it has a Windows
+// executable extension or lives on a Windows drive mounted under /mnt
+func IsWindowsTarget(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, windowsExt := range windowsExts {
+		if ext == windowsExt {
+			return true
+		}
+	}
+	return drivePath.MatchString(path)
+}
+
+// LinkName returns the name a Windows program is installed under by default:
+// its base name without the extension, as on Windows
+func LinkName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// wrapperTemplate runs a Windows program from WSL. Arguments that are paths
+// are converted the way wslpath -w does: /mnt/c/x becomes C:\x and other
+// absolute paths become \\wsl.localhost\<distro>\... . An absolute argument
+// only counts as a path if it exists, is on a drive, or its parent directory
+// exists, so Windows-style switches like /? or /v pass through. Relative
+// paths that exist get backslashes; the Windows program starts in the same
+// directory. --opt=/path values are converted too.
+const wrapperTemplate = `#!/bin/bash
+# Generated by lnb. Runs a Windows program from WSL, converting path
+# arguments to Windows form.
+lnb_winpath() {
+    local path=$1 rest
+    if [[ $path =~ ^/mnt/([a-zA-Z])(/.*)?$ ]]; then
+        rest=${BASH_REMATCH[2]:-/}
+        REPLY="${BASH_REMATCH[1]^^}:${rest//\//\\}"
+    elif [[ $path == /* ]]; then
+        REPLY="\\\\wsl.localhost\\${WSL_DISTRO_NAME:-%s}${path//\//\\}"
+    else
+        REPLY=${path//\//\\}
+    fi
+}
+lnb_is_path() {
+    [[ -e $1 || $1 =~ ^/mnt/[a-zA-Z](/|$) ]] && return 0
+    local parent=${1%%/*}
+    [[ -n $parent && -d $parent ]]
+}
+args=()
+for arg in "$@"; do
+    if [[ $arg == /* ]] && lnb_is_path "$arg"; then
+        lnb_winpath "$arg"; arg=$REPLY
+    elif [[ $arg == -*=/* ]] && lnb_is_path "${arg#*=}"; then
+        lnb_winpath "${arg#*=}"; arg="${arg%%%%=*}=$REPLY"
+    elif [[ $arg == */* && $arg != /* && -e $arg ]]; then
+        lnb_winpath "$arg"; arg=$REPLY
+    fi
+    args+=("$arg")
+done
+exec %s "${args[@]}"
+`
+
+// Wrapper renders the bash script that runs the Windows program at target
+// from WSL. distro is used when WSL_DISTRO_NAME is not set at run time.
+func Wrapper(target, distro string) string {
+	return fmt.Sprintf(wrapperTemplate, distro, "'"+strings.ReplaceAll(target, "'", `'\''`)+"'")
+}
+
+// uncPrefixes are the UNC roots Windows uses for WSL distributions
+var uncPrefixes = []string{`\\wsl.localhost\`, `\\wsl$\`}
+
+// ParseUNC splits a Windows path such as \\wsl.localhost\Ubuntu\usr\bin\jq
+// into the distribution and the Linux path inside it
+func ParseUNC(path string) (distro, linuxPath string, ok bool) {
+	normalized := strings.ReplaceAll(path, "/", `\`)
+	for _, prefix := range uncPrefixes {
+		if len(normalized) < len(prefix) || !strings.EqualFold(normalized[:len(prefix)], prefix) {
+			continue
+		}
+		distro, rest, _ := strings.Cut(normalized[len(prefix):], `\`)
+		if distro == "" {
+			return "", "", false
+		}
+		return distro, "/" + strings.ReplaceAll(rest, `\`, "/"), true
+	}
+	return "", "", false
+}
+
+// Command returns the argv that runs linuxPath in distro from Windows
+func Command(distro, linuxPath string) []string {
+	return []string{"wsl.exe", "-d", distro, "-e", linuxPath}
+}
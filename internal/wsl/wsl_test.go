@@ -0,0 +1,110 @@
+package wsl
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsWindowsTarget(t *testing.T) {
+	testCases := map[string]bool{
+		"/mnt/c/Tools/foo.exe":  true,
+		"/mnt/d/scripts/build":  true,
+		"/home/me/bin/tool.EXE": true,
+		"/home/me/run.cmd":      true,
+		"/usr/bin/jq":           false,
+		"/mnt/data/tool":        false,
+		"/home/me/app.AppImage": false,
+	}
+	for path, want := range testCases {
+		if got := IsWindowsTarget(path); got != want {
+			t.Errorf("IsWindowsTarget(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParseUNC(t *testing.T) {
+	testCases := []struct {
+		path      string
+		distro    string
+		linuxPath string
+		ok        bool
+	}{
+		{`\\wsl.localhost\Ubuntu\usr\bin\jq`, "Ubuntu", "/usr/bin/jq", true},
+		{`\\wsl$\Debian\home\me\bin\tool`, "Debian", "/home/me/bin/tool", true},
+		{`//WSL.LOCALHOST/Ubuntu-22.04/opt/x`, "Ubuntu-22.04", "/opt/x", true},
+		{`\\wsl.localhost\`, "", "", false},
+		{`\\server\share\tool.exe`, "", "", false},
+		{`C:\Tools\tool.exe`, "", "", false},
+	}
+	for _, tc := range testCases {
+		distro, linuxPath, ok := ParseUNC(tc.path)
+		if distro != tc.distro || linuxPath != tc.linuxPath || ok != tc.ok {
+			t.Errorf("ParseUNC(%q) = %q, %q, %v; want %q, %q, %v", tc.path, distro, linuxPath, ok, tc.distro, tc.linuxPath, tc.ok)
+		}
+	}
+}
+
+// TestWrapperConvertsArguments runs the generated wrapper against a fake
+// Windows program that prints the arguments it receives
+func TestWrapperConvertsArguments(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "fake.exe")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\nfor a in \"$@\"; do printf '%s\\n' \"$a\"; done\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wrapper := filepath.Join(dir, "fake")
+	if err := os.WriteFile(wrapper, []byte(Wrapper(fake, "Fallback")), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "in.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		arg  string
+		want string
+	}{
+		{"/mnt/c/Tools/in.txt", `C:\Tools\in.txt`},
+		{"/mnt/d", `D:\`},
+		{dir + "/out.txt", `\\wsl.localhost\Ubuntu` + strings.ReplaceAll(dir, "/", `\`) + `\out.txt`},
+		{"--out=/mnt/e/x y", `--out=E:\x y`},
+		{"sub/in.txt", `sub\in.txt`},
+		{"sub/missing.txt", "sub/missing.txt"},
+		{"/v", "/v"},
+		{"/?", "/?"},
+		{"/no/such/dir/file", "/no/such/dir/file"},
+		{"plain words", "plain words"},
+	}
+
+	args := make([]string, len(testCases))
+	for i, tc := range testCases {
+		args[i] = tc.arg
+	}
+	cmd := exec.Command(wrapper, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "WSL_DISTRO_NAME=Ubuntu")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("wrapper failed: %v", err)
+	}
+
+	got := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(got) != len(testCases) {
+		t.Fatalf("got %d arguments, want %d: %q", len(got), len(testCases), got)
+	}
+	for i, tc := range testCases {
+		if got[i] != tc.want {
+			t.Errorf("argument %q became %q, want %q", tc.arg, got[i], tc.want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package lnb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/names"
+	"lnb/internal/oshandler"
+)
+
+// BundleVersion is the bundle format Export writes and Import reads
+const BundleVersion = "1"
+
+// Bundle is a portable copy of entries made by Export and recreated by Import,
+// possibly on another machine. Paths under the home directory are written as
+// ~/... with forward slashes, and the files lnb generates are left out, since
+// Import creates them again.
+type Bundle struct {
+	Version  string            `json:"lnb_bundle"`
+	Exported time.Time         `json:"exported"`
+	OS       string            `json:"os"` // GOOS of the machine exported from
+	Entries  []*Entry          `json:"entries"`
+	Binaries map[string]string `json:"binaries,omitempty"` // base64 contents of binaries, by entry name
+}
+
+// Encode returns the bundle as the JSON Import reads
+func (b *Bundle) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteFile saves the bundle to path
+func (b *Bundle) WriteFile(path string) error {
+	data, err := b.Encode()
+	if err != nil {
+		return err
+	}
+	return fsops.WriteFile(path, data, 0644)
+}
+
+// Conflict says what Import does with an entry whose name is taken
+type Conflict string
+
+const (
+	ConflictSkip      Conflict = "skip"      // keep the existing entry; the default
+	ConflictOverwrite Conflict = "overwrite" // replace it
+	ConflictRename    Conflict = "rename"    // import under the first free name-2, name-3, ...
+)
+
+// ParseConflict checks a conflict strategy; empty means ConflictSkip
+func ParseConflict(s string) (Conflict, error) {
+	switch conflict := Conflict(strings.ToLower(s)); conflict {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+		return conflict, nil
+	}
+	return "", fmt.Errorf("unknown conflict strategy '%s' (want skip, overwrite or rename)", s)
+}
+
+// Imported says what Import did with one entry of a bundle
+type Imported struct {
+	Name    string  // the entry's name in the bundle
+	Result  *Result // what creating it reported; nil if it was skipped or failed
+	Skipped bool    // the name was taken and the entry was left out
+	Err     error   // why it could not be created
+}
+
+// Export returns the entries matching q as a bundle. With embed, the contents
+// of binaries are included so that Import can recreate them where their
+// source does not exist.
+func (c *Client) Export(q Query, embed bool) (*Bundle, error) {
+	entries, err := c.Find(q)
+	if err != nil {
+		return nil, err
+	}
+	home, _ := os.UserHomeDir()
+
+	bundle := &Bundle{Version: BundleVersion, Exported: time.Now().UTC(), OS: runtime.GOOS, Entries: []*Entry{}}
+	for _, found := range entries {
+		if embed && !found.IsAlias() {
+			data, err := os.ReadFile(found.SourcePath)
+			if err != nil {
+				return nil, fmt.Errorf("cannot embed '%s': %v", found.Name, err)
+			}
+			if bundle.Binaries == nil {
+				bundle.Binaries = make(map[string]string)
+			}
+			bundle.Binaries[found.Name] = base64.StdEncoding.EncodeToString(data)
+		}
+
+		entry, err := portable(found, home)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Entries = append(bundle.Entries, entry)
+	}
+	return bundle, nil
+}
+
+// portable returns a copy of entry fit for another machine: paths under home
+// become ~/..., and what lnb generates on this machine is left out
+func portable(found *Entry, home string) (*Entry, error) {
+	entry, err := copyEntry(found)
+	if err != nil {
+		return nil, err
+	}
+	entry.TargetPath = ""
+	entry.ExtraTargets = nil
+	if entry.Kind == config.KindLocal {
+		// The wrapper calls this machine's lnb; Import points it at the importing one
+		entry.Command = nil
+	}
+	mapPaths(entry, func(path string) string { return toHome(path, home) })
+	return entry, nil
+}
+
+// Import recreates the entries of bundle through the OS handler. An entry
+// whose name is taken is handled as conflict says. It carries on past entries
+// that cannot be created; each one's outcome is in the returned list. The
+// whole import is journaled as one operation, so Undo takes it back.
+func (c *Client) Import(bundle *Bundle, conflict Conflict) ([]*Imported, error) {
+	if bundle.Version != BundleVersion {
+		return nil, errorf(ErrInvalidBundle, "unsupported bundle version '%s' (want %s)", bundle.Version, BundleVersion)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Settle every name first, so that renamed entries don't take each other's
+	taken := make([]string, 0, len(cfg.Entries))
+	for name := range cfg.Entries {
+		taken = append(taken, name)
+	}
+	var imported []*Imported
+	targets := make([]string, len(bundle.Entries))  // the name each entry is created as; empty if it is not
+	replaces := make([]string, len(bundle.Entries)) // the entry each one overwrites, if any
+	var touched []string
+	for i, entry := range bundle.Entries {
+		item := &Imported{Name: entry.Name}
+		imported = append(imported, item)
+		if err := names.Validate(entry.Name, runtime.GOOS); err != nil {
+			item.Err = err
+			continue
+		}
+		target := entry.Name
+		if existing, clash := takenAs(target, taken); clash {
+			switch conflict {
+			case ConflictSkip:
+				item.Skipped = true
+				continue
+			case ConflictOverwrite:
+				replaces[i] = existing
+				if existing != target {
+					// Only on case-insensitive systems, where the two differ in case
+					touched = append(touched, existing)
+				}
+			case ConflictRename:
+				for n := 2; clash; n++ {
+					target = entry.Name + "-" + strconv.Itoa(n)
+					_, clash = takenAs(target, taken)
+				}
+			}
+		}
+		targets[i] = target
+		taken = append(taken, target)
+		touched = append(touched, target)
+	}
+	p := c.begin("import", touched...)
+
+	var last *Result
+	for i, entry := range bundle.Entries {
+		if targets[i] == "" {
+			continue
+		}
+		result, err := c.importEntry(entry, targets[i], replaces[i], bundle.Binaries[entry.Name], home)
+		if err != nil {
+			imported[i].Err = err
+			continue
+		}
+		imported[i].Result = result
+		last = result
+	}
+	if last != nil {
+		p.finish(last)
+	}
+	return imported, nil
+}
+
+// takenAs returns the name in taken that name would clash with: the same
+// name, or on case-insensitive systems one differing only in case
+func takenAs(name string, taken []string) (string, bool) {
+	for _, other := range taken {
+		if other == name || (names.CaseInsensitive(runtime.GOOS) && strings.EqualFold(other, name)) {
+			return other, true
+		}
+	}
+	return "", false
+}
+
+// importEntry creates one entry of a bundle as name, replacing the entry
+// called replace if that is set. embedded holds the binary's contents, if any.
+func (c *Client) importEntry(exported *Entry, name, replace, embedded, home string) (*Result, error) {
+	entry, err := copyEntry(exported)
+	if err != nil {
+		return nil, err
+	}
+	mapPaths(entry, func(path string) string { return fromHome(path, home) })
+	entry.Name = name
+	entry.Origin = config.OriginImported
+
+	var command *Command
+	switch entry.Kind {
+	case config.KindContainer:
+		if entry.Container == nil {
+			return nil, errorf(ErrInvalidBundle, "container alias '%s' has no container", name)
+		}
+		if err := entry.Container.Validate(); err != nil {
+			return nil, errorf(ErrInvalidBundle, "container alias '%s' is invalid: %v", name, err)
+		}
+		command = entry.Container.Command(runtime.GOOS)
+	case config.KindLocal:
+		runner, err := c.runner()
+		if err != nil {
+			return nil, err
+		}
+		entry.Command = config.NewCommand(runner, "run", name)
+		command = entry.Command
+	case config.KindAlias:
+		if entry.Command == nil || len(entry.Command.Argv) == 0 {
+			return nil, errorf(ErrInvalidBundle, "alias '%s' has no command", name)
+		}
+		command = entry.Command
+	default:
+		if err := c.placeBinary(entry, embedded); err != nil {
+			return nil, err
+		}
+	}
+
+	warnings, err := c.checkShadow(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	existing, replacing := cfg.GetEntry(replace)
+	if replacing {
+		if _, err := c.uninstall(existing); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := c.reinstall(entry, name, command, oshandler.EntryOptions(entry))
+	if err != nil {
+		if replacing {
+			return nil, c.restore(existing, err)
+		}
+		return nil, err
+	}
+	if err := keepHistory(entry, name, false); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to update config: %v", err))
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
+
+// placeBinary makes sure the source of an imported binary exists. One that is
+// missing is written from the bundle under the config directory, if it was
+// embedded.
+func (c *Client) placeBinary(entry *Entry, embedded string) error {
+	if _, err := fsops.Stat(entry.SourcePath); err == nil {
+		return nil
+	}
+	if embedded == "" {
+		return errorf(ErrNotExist, "'%s' does not exist on this machine (export with --embed to carry binaries along)", entry.SourcePath)
+	}
+	data, err := base64.StdEncoding.DecodeString(embedded)
+	if err != nil {
+		return errorf(ErrInvalidBundle, "the embedded binary of '%s' is damaged: %v", entry.Name, err)
+	}
+
+	dir, err := config.DataPath(filepath.Join("binaries", entry.Name))
+	if err != nil {
+		return err
+	}
+	if err := fsops.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, filepath.Base(filepath.FromSlash(entry.SourcePath)))
+	if err := fsops.WriteFile(path, data, 0755); err != nil {
+		return err
+	}
+	entry.SourcePath = path
+	return nil
+}
+
+// copyEntry returns a deep copy of an entry
+func copyEntry(entry *Entry) (*Entry, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var copied Entry
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// mapPaths rewrites the paths an entry refers to: its source, and the
+// program, arguments and directory of its commands
+func mapPaths(entry *Entry, rewrite func(string) string) {
+	if entry.SourcePath != "" {
+		entry.SourcePath = rewrite(entry.SourcePath)
+	}
+	commands := []*Command{entry.Command, entry.Fallback}
+	for _, variant := range entry.Variants {
+		commands = append(commands, variant)
+	}
+	for _, command := range commands {
+		if command == nil || command.Shell {
+			continue
+		}
+		for i, arg := range command.Argv {
+			command.Argv[i] = rewrite(arg)
+		}
+		if command.Dir != "" {
+			command.Dir = rewrite(command.Dir)
+		}
+	}
+}
+
+// toHome writes a path under home as ~/... with forward slashes; other
+// strings are returned unchanged
+func toHome(path, home string) string {
+	if home == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(home, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return "~"
+	}
+	return "~/" + filepath.ToSlash(rel)
+}
+
+// fromHome turns a ~/... path from a bundle into one under home
+func fromHome(path, home string) string {
+	if path == "~" {
+		return home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, filepath.FromSlash(path[2:]))
+	}
+	return path
+}
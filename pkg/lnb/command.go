@@ -0,0 +1,217 @@
+package lnb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/winpath"
+)
+
+// parseShellArgs parses a command string into arguments while respecting quotes
+func parseShellArgs(command string) []string {
+	// First check if the entire command is a valid file path
+	// This handles cases like "/Applications/Visual Studio Code.app"
+	if _, err := os.Stat(command); err == nil {
+		return []string{command}
+	}
+
+	var args []string
+	var current strings.Builder
+	var inQuotes bool
+	var quoteChar rune
+
+	for _, char := range command {
+		switch char {
+		case '"', '\'':
+			if !inQuotes {
+				inQuotes = true
+				quoteChar = char
+				current.WriteRune(char) // Keep the quote in the argument
+			} else if char == quoteChar {
+				inQuotes = false
+				current.WriteRune(char) // Keep the closing quote
+				quoteChar = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case ' ', '\t':
+			if inQuotes {
+				current.WriteRune(char)
+			} else {
+				if current.Len() > 0 {
+					args = append(args, current.String())
+					current.Reset()
+				}
+			}
+		default:
+			current.WriteRune(char)
+		}
+	}
+
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// normalizeCommand validates a command and resolves the few things lnb
+// resolves when an alias is created, so the wrapper does not depend on the
+// directory it was created from:
+//
+//   - the program, when given as a path, must exist; a relative path is made
+//     absolute, while ~/... is only checked and left for the wrapper to expand
+//   - arguments written as ./x or ../x (also .\x and ..\x on Windows) that
+//     exist are made absolute
+//
+// Everything else, including $VAR, $(...), backticks, ~, globs and words like
+// foo.bar, reaches the wrapper unchanged and is evaluated each time it runs.
+// An exec-mode command from separate arguments is an exact argv, so only the
+// program and ./ arguments are touched there too.
+func normalizeCommand(command *config.Command) (*config.Command, error) {
+	if command == nil || strings.TrimSpace(command.Program()) == "" {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+
+	if command.Shell {
+		line, err := normalizeLine(command.Program())
+		if err != nil {
+			return nil, err
+		}
+		normalized := *command
+		normalized.Argv = []string{line}
+		return &normalized, nil
+	}
+
+	program, err := resolveProgram(command.Program())
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := *command
+	normalized.Argv = []string{program}
+	for _, arg := range command.Argv[1:] {
+		normalized.Argv = append(normalized.Argv, resolveArg(arg))
+	}
+	return &normalized, nil
+}
+
+// normalizeLine applies the normalizeCommand rules to the words of a shell
+// command line, keeping their quotes
+func normalizeLine(command string) (string, error) {
+	// Parse the command to extract the main executable
+	args := parseShellArgs(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("could not parse command")
+	}
+
+	changed := false
+	for i, arg := range args {
+		// Remove quotes if present to check the actual path
+		word, quote := arg, ""
+		if len(word) >= 2 && (word[0] == '"' || word[0] == '\'') && word[len(word)-1] == word[0] {
+			quote = word[:1]
+			word = word[1 : len(word)-1]
+		}
+
+		resolved := word
+		if i == 0 {
+			program, err := resolveProgram(word)
+			if err != nil {
+				return "", err
+			}
+			resolved = program
+		} else {
+			resolved = resolveArg(word)
+		}
+		if resolved == word {
+			continue
+		}
+
+		if quote == "" && strings.ContainsAny(resolved, " \t") {
+			quote = `"`
+		}
+		args[i] = quote + resolved + quote
+		changed = true
+	}
+
+	if !changed {
+		return command, nil
+	}
+	return strings.Join(args, " "), nil
+}
+
+// resolveProgram checks an executable path exists and makes it absolute. Bare
+// command names are returned unchanged for lookup on PATH, and ~ and
+// variables are left for the wrapper to expand.
+func resolveProgram(cmdName string) (string, error) {
+	if cmdName == "~" || strings.HasPrefix(cmdName, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not get home directory: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(homeDir, cmdName[1:])); err != nil {
+			return "", fmt.Errorf("file not found: %s", cmdName)
+		}
+		return cmdName, nil
+	}
+	if strings.ContainsAny(cmdName, "$`%") {
+		return cmdName, nil
+	}
+
+	cmdName = fromShellPath(cmdName)
+
+	// Check if this looks like a path (contains path separators)
+	isPath := strings.Contains(cmdName, "/") || strings.Contains(cmdName, "\\")
+	if !isPath {
+		// A bare command name is executed as-is from PATH; just do basic sanity checks
+		if strings.ContainsAny(cmdName, "{}[]()<>|&;") {
+			return "", fmt.Errorf("command '%s' contains potentially dangerous characters", cmdName)
+		}
+		return cmdName, nil
+	}
+
+	// This appears to be a path, validate it exists and convert to absolute
+	absPath, err := filepath.Abs(cmdName)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve path '%s': %v", cmdName, err)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return "", fmt.Errorf("file not found: %s", absPath)
+	}
+	return absPath, nil
+}
+
+// resolveArg makes an argument explicitly written as a relative path absolute
+// if it exists, and returns any other argument unchanged
+func resolveArg(arg string) string {
+	prefixes := []string{"./", "../"}
+	if runtime.GOOS == "windows" {
+		prefixes = append(prefixes, `.\`, `..\`)
+	}
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(arg, prefix) {
+			continue
+		}
+		if absPath, err := filepath.Abs(arg); err == nil {
+			if _, err := os.Stat(absPath); err == nil {
+				return absPath
+			}
+		}
+	}
+	return arg
+}
+
+// fromShellPath turns a /c/... path typed in Git Bash, Cygwin or WSL into
+// C:\... when running on Windows, where the Windows APIs cannot resolve it
+func fromShellPath(path string) string {
+	if runtime.GOOS != "windows" {
+		return path
+	}
+	converted, _ := winpath.FromPosix(path)
+	return converted
+}
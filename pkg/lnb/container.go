@@ -0,0 +1,72 @@
+package lnb
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/names"
+)
+
+// Container describes what a container alias runs
+type Container = config.Container
+
+// Container runtimes for Container.Runtime
+const (
+	RuntimeDocker = config.RuntimeDocker
+	RuntimePodman = config.RuntimePodman
+)
+
+// DefaultRuntime returns docker if it is on PATH, otherwise podman if that
+// is, otherwise docker
+func DefaultRuntime() string {
+	for _, candidate := range []string{RuntimeDocker, RuntimePodman} {
+		if _, err := exec.LookPath(candidate); err == nil {
+			return candidate
+		}
+	}
+	return RuntimeDocker
+}
+
+// ContainerAlias creates a wrapper named name that runs spec.Image with the
+// current directory mounted and the wrapper's arguments appended. An empty
+// spec.Runtime means DefaultRuntime.
+func (c *Client) ContainerAlias(name string, spec *Container) (*Result, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errorf(ErrInvalidName, "alias name cannot be empty")
+	}
+
+	resolved := *spec
+	if resolved.Runtime == "" {
+		resolved.Runtime = DefaultRuntime()
+	}
+	if err := resolved.Validate(); err != nil {
+		return nil, errorf(ErrInvalidCommand, "invalid container alias: %v", err)
+	}
+
+	if err := names.Validate(name, runtime.GOOS); err != nil {
+		return nil, err
+	}
+	warnings, err := c.checkShadow(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath(resolved.Runtime); err != nil {
+		warnings = append(warnings, resolved.Runtime+" was not found on PATH; the alias will fail until it is installed")
+	}
+
+	opts, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	opts.Container = &resolved
+	p := c.begin("alias", name)
+	result, err := c.handler.HandleAlias(name, resolved.Command(runtime.GOOS), "install", opts)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package lnb
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"lnb/internal/config"
+	"lnb/internal/winpath"
+)
+
+// Issue is a single problem found by Doctor
+type Issue struct {
+	Name    string // entry name, empty for problems not tied to an entry
+	Problem string
+}
+
+// Report is the outcome of Doctor
+type Report struct {
+	BinDir       string
+	BinDirInPath bool
+	Checked      int
+	Issues       []Issue
+}
+
+// OK reports whether Doctor found nothing to fix
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Doctor checks that the bin directory is on PATH and that every entry in the
+// config still has its target and, for binaries, its source
+func (c *Client) Doctor() (*Report, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{BinDir: c.handler.BinDir()}
+	report.BinDirInPath = inPath(report.BinDir)
+	if !report.BinDirInPath {
+		report.Issues = append(report.Issues, Issue{Problem: report.BinDir + " is not in PATH"})
+	}
+
+	entries := cfg.List()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	for _, entry := range entries {
+		report.Checked++
+		for _, problem := range checkEntry(entry) {
+			report.Issues = append(report.Issues, Issue{Name: entry.Name, Problem: problem})
+		}
+		for _, path := range c.Which(entry.Name).ShadowedBy() {
+			report.Issues = append(report.Issues, Issue{Name: entry.Name, Problem: "shadowed by " + path})
+		}
+	}
+
+	return report, nil
+}
+
+// checkEntry returns the problems found with a single entry
+func checkEntry(entry *config.LnbEntry) []string {
+	var problems []string
+
+	info, err := os.Lstat(entry.TargetPath)
+	if err != nil {
+		return append(problems, "target "+entry.TargetPath+" is missing")
+	}
+
+	// Binaries and shims point at a source file; aliases do not
+	if entry.SourcePath == "" {
+		return problems
+	}
+
+	if _, err := os.Stat(entry.SourcePath); err != nil {
+		problems = append(problems, "source "+entry.SourcePath+" is missing")
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if dest, err := os.Readlink(entry.TargetPath); err == nil && dest != entry.SourcePath {
+			problems = append(problems, "symlink points to "+dest+" instead of "+entry.SourcePath)
+		}
+	}
+
+	return problems
+}
+
+// inPath reports whether dir is listed in the current PATH
+func inPath(dir string) bool {
+	if runtime.GOOS == "windows" {
+		return winpath.Contains(os.Getenv("PATH"), dir, os.Getenv)
+	}
+
+	want := filepath.Clean(dir)
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if p != "" && filepath.Clean(p) == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package lnb
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"lnb/internal/config"
+	"lnb/internal/oshandler"
+	"lnb/internal/project"
+)
+
+// Spec is the part of an alias that can be changed after it was created:
+// the command of a plain alias, or the container of a container alias
+type Spec struct {
+	Command   *Command            `json:"command,omitempty"`
+	Variants  map[string]*Command `json:"variants,omitempty"` // run instead of Command on these GOOS
+	Container *Container          `json:"container,omitempty"`
+	Literal   bool                `json:"literal,omitempty"` // store Command exactly as given
+}
+
+// Spec returns the editable part of the alias called name
+func (c *Client) Spec(name string) (*Spec, error) {
+	entry, err := c.editable(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Kind == config.KindContainer {
+		return &Spec{Container: entry.Container}, nil
+	}
+	return &Spec{Command: entry.Command, Variants: entry.Variants, Literal: entry.Literal}, nil
+}
+
+// Update replaces the command of the alias called name; see Edit. Variants
+// in Options replace those of the alias, which keeps its own otherwise.
+func (c *Client) Update(name string, command *Command) (*Result, error) {
+	spec := &Spec{Command: command, Variants: c.Options.Variants, Literal: c.Options.Literal}
+	if len(spec.Variants) == 0 {
+		entry, err := c.editable(name)
+		if err != nil {
+			return nil, err
+		}
+		spec.Variants = entry.Variants
+	}
+	return c.Edit(name, spec)
+}
+
+// Edit replaces what the alias called name runs with spec and rewrites its
+// wrapper where it is. The entry keeps its name, tags, launchers, creation
+// time and bin dir, and records when it was changed. If the new wrapper cannot
+// be written the old one is put back.
+func (c *Client) Edit(name string, spec *Spec) (*Result, error) {
+	entry, err := c.editable(name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := oshandler.EntryOptions(entry)
+	opts.Literal = spec.Literal
+	if c.Options.Shim != "" {
+		opts.Shim = c.Options.Shim
+	}
+	opts.Posix = opts.Posix || c.Options.Posix
+
+	var command *Command
+	switch {
+	case entry.Kind == config.KindContainer:
+		if spec.Container == nil || spec.Command != nil || len(spec.Variants) > 0 {
+			return nil, errorf(ErrInvalidCommand, "'%s' is a container alias; change its container, not its command", entry.Name)
+		}
+		resolved := *spec.Container
+		if resolved.Runtime == "" {
+			resolved.Runtime = DefaultRuntime()
+		}
+		if err := resolved.Validate(); err != nil {
+			return nil, errorf(ErrInvalidCommand, "invalid container alias: %v", err)
+		}
+		opts.Literal = false
+		opts.Container = &resolved
+		command = resolved.Command(runtime.GOOS)
+	case spec.Container != nil:
+		return nil, errorf(ErrInvalidCommand, "'%s' is not a container alias; it cannot be given a container", entry.Name)
+	default:
+		if command, err = prepareCommand(spec.Command, spec.Literal); err != nil {
+			return nil, err
+		}
+		if opts.Variants, err = prepareVariants(spec.Variants, spec.Literal); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := *entry
+	updated.Command = command
+	updated.Variants = opts.Variants
+	updated.Literal = opts.Literal
+	updated.Container = opts.Container
+	if runtime.GOOS == "windows" {
+		updated.Shim = opts.Shim
+		if updated.Shim == config.ShimCmd {
+			updated.Shim = ""
+		}
+		updated.PosixShim = opts.Posix
+	}
+	now := time.Now()
+	updated.UpdatedAt = &now
+
+	p := c.begin("edit", entry.Name)
+	result, err := oshandler.Rewrite(c.handler, entry, &updated)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	return result, nil
+}
+
+// editable finds the entry called name and checks it is an alias
+func (c *Client) editable(name string) (*Entry, error) {
+	entry, err := c.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Kind == config.KindLocal {
+		return nil, errorf(ErrNotAlias, "'%s' is a local alias; change it with 'lnb alias --local' or in its %s", entry.Name, project.FileName)
+	}
+	if entry.Kind != config.KindAlias && entry.Kind != config.KindContainer {
+		return nil, errorf(ErrNotAlias, "'%s' is a %s, not an alias; only aliases can be edited", entry.Name, entry.Kind)
+	}
+	return entry, nil
+}
+
+// restore recreates an entry removed by Import or LocalAlias after recreating it in
+// its new form failed with err, and returns the error to report
+func (c *Client) restore(entry *Entry, err error) error {
+	if _, restoreErr := c.reinstall(entry, entry.Name, entry.Command, oshandler.EntryOptions(entry)); restoreErr != nil {
+		return fmt.Errorf("%w (restoring '%s' also failed: %v)", err, entry.Name, restoreErr)
+	}
+	if restoreErr := keepHistory(entry, entry.Name, false); restoreErr != nil {
+		return fmt.Errorf("%w (restoring '%s' also failed: %v)", err, entry.Name, restoreErr)
+	}
+	return err
+}
+
+// reinstall creates entry again as name through the OS handler. Aliases run
+// command; binaries link their recorded source.
+func (c *Client) reinstall(entry *Entry, name string, command *Command, opts Options) (*Result, error) {
+	if entry.IsAlias() {
+		return c.handler.HandleAlias(name, command, "install", opts)
+	}
+	return c.handler.Handle(entry.SourcePath, name, "install", opts)
+}
+
+// keepHistory copies what a regenerated entry should keep from old onto the
+// entry now called name, and stamps it as changed if changed is set
+func keepHistory(old *Entry, name string, changed bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	entry, exists := cfg.GetEntry(name)
+	if !exists {
+		return fmt.Errorf("'%s' is missing from the config", name)
+	}
+
+	entry.InstalledAt = old.InstalledAt
+	entry.UpdatedAt = old.UpdatedAt
+	entry.Origin = old.Origin
+	entry.Tags = old.Tags
+	if changed {
+		now := time.Now()
+		entry.UpdatedAt = &now
+	}
+	return cfg.Save()
+}
@@ -0,0 +1,34 @@
+package lnb
+
+import (
+	"errors"
+
+	"lnb/internal/oshandler"
+)
+
+// Errors returned by Client methods. Every error a Client returns for one of
+// these conditions matches the corresponding value with errors.Is.
+var (
+	ErrUnsupportedOS    = errors.New("unsupported operating system")
+	ErrInvalidName      = oshandler.ErrInvalidName
+	ErrShadowed         = errors.New("shadows another command on PATH")
+	ErrInvalidCommand   = oshandler.ErrInvalidCommand
+	ErrNotExist         = oshandler.ErrNotExist
+	ErrNotExecutable    = oshandler.ErrNotExecutable
+	ErrAlreadyInstalled = oshandler.ErrAlreadyInstalled
+	ErrTargetExists     = oshandler.ErrTargetExists
+	ErrNotInstalled     = oshandler.ErrNotInstalled
+	ErrTargetMismatch   = oshandler.ErrTargetMismatch
+	ErrNotAlias         = errors.New("not an alias")
+	ErrInvalidQuery     = errors.New("invalid query")
+	ErrInvalidTag       = errors.New("invalid tag")
+	ErrNoProfile        = errors.New("no such profile")
+	ErrProfileInUse     = errors.New("profile in use")
+	ErrUntrusted        = errors.New("untrusted project file")
+	ErrNoRecord         = errors.New("no such operation in the history")
+	ErrChanged          = errors.New("changed since the operation")
+	ErrInvalidBundle    = errors.New("invalid bundle")
+)
+
+// errorf formats a user-facing error that matches kind with errors.Is
+var errorf = oshandler.Errorf
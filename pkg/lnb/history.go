@@ -0,0 +1,223 @@
+package lnb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/journal"
+)
+
+// Record is one operation in the journal: what it did to each entry it
+// touched, with the entries as they were before and after
+type Record = journal.Record
+
+// Change is what a Record did to one entry
+type Change = journal.Change
+
+// History returns every operation recorded in the journal, oldest first
+func (c *Client) History() ([]*Record, error) {
+	return journal.Load()
+}
+
+// Undo reverses the operation with the given id through the OS handler: the
+// entries it created or changed are removed and those it removed or changed
+// are created again as they were, with any file contents it replaced; one that
+// cannot be created keeps its record and is reported with a warning. An id
+// of 0 means the latest operation that is neither an undo nor undone already;
+// undoing an undo redoes what it reversed. The undo is journaled itself. It
+// returns the operation undone and what creating each entry again reported.
+//
+// Only an operation whose entries have not changed since can be undone, so
+// later operations on the same entries have to be undone first.
+func (c *Client) Undo(id int) (*Record, []*Result, error) {
+	records, err := journal.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	record, err := undoable(records, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Profile != record.Profile {
+		profile := record.Profile
+		if profile == "" {
+			profile = DefaultProfile
+		}
+		return nil, nil, errorf(ErrProfileInUse, "#%d was made in profile '%s'; switch to it with 'lnb profile use %s' first", record.ID, profile, profile)
+	}
+
+	var after, before []*Entry
+	for _, change := range record.Changes {
+		current, _ := cfg.GetEntry(change.Name)
+		if !sameEntry(current, change.After) {
+			return nil, nil, errorf(ErrChanged, "'%s' has changed since #%d; undo the later operations on it first", change.Name, record.ID)
+		}
+		if current != nil {
+			after = append(after, current)
+		}
+		if change.Before != nil {
+			before = append(before, change.Before)
+		}
+	}
+
+	p := c.begin("undo", record.Names()...)
+	p.undoes = record.ID
+	if err := c.takeDown(after); err != nil {
+		return nil, nil, err
+	}
+	results := c.bringUp(before)
+
+	var warnings []string
+	for _, change := range record.Changes {
+		for _, file := range change.Files {
+			if err := putBack(file); err != nil {
+				warnings = append(warnings, fmt.Sprintf("failed to restore %s: %v", file.Path, err))
+			}
+		}
+	}
+	if err := p.done(); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if len(warnings) > 0 {
+		if len(results) == 0 {
+			results = append(results, &Result{Name: record.Changes[0].Name})
+		}
+		results[0].Warnings = append(results[0].Warnings, warnings...)
+	}
+	return record, results, nil
+}
+
+// undoable picks the record Undo reverses
+func undoable(records []*Record, id int) (*Record, error) {
+	if id == 0 {
+		for i := len(records) - 1; i >= 0; i-- {
+			record := records[i]
+			if _, undone := journal.UndoneBy(records, record.ID); record.Op != "undo" && !undone {
+				return record, nil
+			}
+		}
+		return nil, errorf(ErrNoRecord, "there is nothing to undo")
+	}
+
+	record, found := journal.Find(records, id)
+	if !found {
+		return nil, errorf(ErrNoRecord, "there is no operation #%d in the history", id)
+	}
+	if undo, undone := journal.UndoneBy(records, id); undone {
+		return nil, errorf(ErrChanged, "#%d was already undone by #%d", id, undo.ID)
+	}
+	return record, nil
+}
+
+// sameEntry reports whether two records of an entry are the same; nil means
+// the entry does not exist
+func sameEntry(a, b *Entry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// putBack writes the recorded contents of a file lnb created again, if they
+// differ from what it created. A file that was not created again is left alone.
+func putBack(file journal.File) error {
+	info, err := fsops.Lstat(file.Path)
+	if os.IsNotExist(err) || (err == nil && !info.Mode().IsRegular()) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := fsops.ReadFile(file.Path)
+	if err != nil || string(data) == file.Content {
+		return err
+	}
+	return fsops.WriteFile(file.Path, []byte(file.Content), info.Mode().Perm())
+}
+
+// pending is an operation being journaled: begin records the entries it may
+// touch as they are before it, and done records them as they are after it
+type pending struct {
+	op      string
+	undoes  int
+	profile string
+	changes []*journal.Change
+	err     error
+}
+
+// begin starts journaling the operation op on the entries called names. Names
+// without an entry are ones the operation may create.
+func (c *Client) begin(op string, names ...string) *pending {
+	p := &pending{op: op}
+	cfg, err := config.Load()
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.profile = cfg.Profile
+
+	for _, name := range names {
+		change := &journal.Change{Name: name}
+		if entry, exists := cfg.GetEntry(name); exists {
+			change.Before = entry
+			files, err := c.files(entry)
+			if err != nil {
+				p.err = err
+				return p
+			}
+			for _, file := range files {
+				if file.Exists && file.Link == "" && !file.Binary {
+					change.Files = append(change.Files, journal.File{Path: file.Path, Content: file.Content})
+				}
+			}
+		}
+		p.changes = append(p.changes, change)
+	}
+	return p
+}
+
+// done appends the operation to the journal once it succeeded. Entries it
+// left as they were are dropped, and so is an operation that changed nothing.
+func (p *pending) done() error {
+	if p.err != nil {
+		return fmt.Errorf("failed to record the change in the journal: %v", p.err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to record the change in the journal: %v", err)
+	}
+
+	record := &journal.Record{Op: p.op, Profile: p.profile, Undoes: p.undoes}
+	for _, change := range p.changes {
+		change.After, _ = cfg.GetEntry(change.Name)
+		if !sameEntry(change.Before, change.After) {
+			record.Changes = append(record.Changes, change)
+		}
+	}
+	if len(record.Changes) == 0 {
+		return nil
+	}
+	if err := journal.Append(record); err != nil {
+		return fmt.Errorf("failed to record the change in the journal: %v", err)
+	}
+	return nil
+}
+
+// finish is done for operations that return a Result: a journal that cannot
+// be written is reported as a warning, since the operation itself succeeded
+func (p *pending) finish(result *Result) {
+	if err := p.done(); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+}
@@ -0,0 +1,161 @@
+package lnb
+
+import (
+	"bytes"
+	"os"
+	"unicode/utf8"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/textdiff"
+)
+
+// Info is everything lnb knows about a single entry
+type Info struct {
+	Entry      *Entry
+	Files      []*File     // the files lnb generated for the entry, its target first
+	Problems   []string    // what Doctor reports for the entry
+	Resolution *Resolution // the entry's name looked up on PATH
+}
+
+// Healthy reports whether the entry has no problems and its files are what
+// lnb would generate today
+func (i *Info) Healthy() bool {
+	if len(i.Problems) > 0 {
+		return false
+	}
+	for _, file := range i.Files {
+		if file.Stale() {
+			return false
+		}
+	}
+	return true
+}
+
+// File is a file lnb generated for an entry, as it is on disk and as lnb
+// would write it today
+type File struct {
+	Path    string
+	Exists  bool
+	Link    string // where the file points, if it is a symlink
+	Content string // the file's contents, if it is not a symlink
+	Binary  bool   // Content is not text, e.g. an icon
+
+	// Regenerated is set when lnb can render the file again; Expected and
+	// ExpectedLink are then what it would write
+	Regenerated  bool
+	Expected     string
+	ExpectedLink string
+}
+
+// Stale reports whether the file differs from what lnb would write today.
+// Files lnb cannot regenerate are never stale.
+func (f *File) Stale() bool {
+	if !f.Regenerated {
+		return false
+	}
+	if !f.Exists {
+		return true
+	}
+	if f.ExpectedLink != "" {
+		return f.Link != f.ExpectedLink
+	}
+	return f.Link != "" || f.Content != f.Expected
+}
+
+// Diff returns the differences between the file on disk and what lnb would
+// write today as a unified diff, or "" if there are none. Symlinks are shown
+// as a single "-> destination" line.
+func (f *File) Diff() string {
+	if !f.Stale() {
+		return ""
+	}
+	current, expected := f.Content, f.Expected
+	if f.Link != "" {
+		current = "-> " + f.Link + "\n"
+	}
+	if f.ExpectedLink != "" {
+		expected = "-> " + f.ExpectedLink + "\n"
+	}
+	return textdiff.Unified(f.Path+" (on disk)", f.Path+" (lnb today)", current, expected)
+}
+
+// Info describes the entry called name: its files, its health and how its
+// name resolves on PATH
+func (c *Client) Info(name string) (*Info, error) {
+	entry, err := c.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	files, err := c.files(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		Entry:      entry,
+		Files:      files,
+		Problems:   checkEntry(entry),
+		Resolution: c.Which(entry.Name),
+	}, nil
+}
+
+// Files returns the files lnb generated for the entry called name, its
+// target first
+func (c *Client) Files(name string) ([]*File, error) {
+	entry, err := c.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.files(entry)
+}
+
+// files reads an entry's files from disk and pairs them with what the
+// handler would write today
+func (c *Client) files(entry *config.LnbEntry) ([]*File, error) {
+	var files []*File
+	byPath := make(map[string]*File)
+	add := func(path string) *File {
+		if file, ok := byPath[path]; ok {
+			return file
+		}
+		file := &File{Path: path}
+		files = append(files, file)
+		byPath[path] = file
+		return file
+	}
+
+	add(entry.TargetPath)
+	for _, extra := range entry.ExtraTargets {
+		add(extra)
+	}
+	for _, expected := range c.handler.Files(entry) {
+		file := add(expected.Path)
+		file.Regenerated = true
+		file.Expected = expected.Content
+		file.ExpectedLink = expected.Link
+	}
+
+	for _, file := range files {
+		info, err := fsops.Lstat(file.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		file.Exists = true
+		if info.Mode()&os.ModeSymlink != 0 {
+			if file.Link, err = fsops.Readlink(file.Path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data, err := fsops.ReadFile(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		file.Content = string(data)
+		file.Binary = !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0
+	}
+	return files, nil
+}
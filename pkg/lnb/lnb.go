@@ -0,0 +1,321 @@
+// Package lnb is the programmatic interface to lnb. It installs binaries and
+// creates aliases through the same OS handlers the lnb CLI uses, but never
+// prints or exits: every method returns a structured result or an error that
+// can be matched with errors.Is against the Err* values in this package.
+package lnb
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/names"
+	"lnb/internal/oshandler"
+)
+
+// Entry is a binary or alias recorded in the lnb config
+type Entry = config.LnbEntry
+
+// Command is the structured command an alias runs
+type Command = config.Command
+
+// ParseCommand splits a command line into a Command, keeping lines that need a
+// shell (pipes, substitutions, globs) in shell mode
+func ParseCommand(line string) *Command {
+	return config.ParseCommand(line)
+}
+
+// LiteralCommand returns a shell-mode Command that runs line exactly as written
+func LiteralCommand(line string) *Command {
+	return &Command{Argv: []string{line}, Shell: true}
+}
+
+// VariantOSes are the operating systems an alias can have a command variant
+// for in Options.Variants
+var VariantOSes = config.VariantOSes
+
+// Options tune how Install and Alias create entries; see Client.Options
+type Options = oshandler.Options
+
+// ShimStyle says which launchers are generated for an entry on Windows
+type ShimStyle = config.ShimStyle
+
+// Shim styles for Options.Shim
+const (
+	ShimCmd  = config.ShimCmd  // a .cmd or .bat file, the default
+	ShimPS1  = config.ShimPS1  // a PowerShell script only
+	ShimBoth = config.ShimBoth // both of the above
+)
+
+// ParseShimStyle checks a shim style name; empty means ShimCmd
+func ParseShimStyle(s string) (ShimStyle, error) {
+	return config.ParseShimStyle(s)
+}
+
+// Result describes what an Install, Alias or Remove call did
+type Result = oshandler.Result
+
+// Client manages binaries and aliases for the current OS
+type Client struct {
+	// AllowShadow lets Install and Alias create an entry whose name is also
+	// found elsewhere on PATH; the collision is reported as a warning instead
+	// of a ShadowError
+	AllowShadow bool
+
+	// Options apply to every entry Install and Alias create
+	Options Options
+
+	// Runner is the lnb executable the wrappers of local aliases call with
+	// "run <name>". Empty means the running program, which is right for the
+	// lnb CLI; other programs using this package must set it.
+	Runner string
+
+	handler oshandler.Handler
+}
+
+// New returns a Client for the current operating system
+func New() (*Client, error) {
+	handler := oshandler.New()
+	if handler == nil {
+		return nil, ErrUnsupportedOS
+	}
+	return &Client{handler: handler}, nil
+}
+
+// BinDir returns the directory lnb writes symlinks and wrappers to
+func (c *Client) BinDir() string {
+	return c.handler.BinDir()
+}
+
+// Install makes the binary at path globally accessible under its own name
+func (c *Client) Install(path string) (*Result, error) {
+	return c.InstallAs(path, "")
+}
+
+// InstallAs makes the binary at path globally accessible as name. An empty
+// name means the binary's file name.
+func (c *Client) InstallAs(path, name string) (*Result, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errorf(ErrNotExist, "file path cannot be empty")
+	}
+	path = fromShellPath(path)
+	if _, err := fsops.Stat(path); os.IsNotExist(err) {
+		return nil, errorf(ErrNotExist, "file '%s' does not exist", path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	linkName := name
+	if linkName == "" {
+		linkName = c.handler.LinkName(absPath)
+	}
+	if err := names.Validate(linkName, runtime.GOOS); err != nil {
+		return nil, err
+	}
+	opts, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := c.checkShadow(linkName)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.begin("install", linkName)
+	result, err := c.handler.Handle(absPath, name, "install", opts)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
+
+// Alias creates a wrapper named name that runs the command line command.
+// A relative executable path in command is resolved to an absolute path
+// first; see AliasCommand. With Options.Literal the line is stored exactly.
+func (c *Client) Alias(name, command string) (*Result, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, errorf(ErrInvalidCommand, "invalid command '%s': command cannot be empty", command)
+	}
+	if c.Options.Literal {
+		return c.AliasCommand(name, LiteralCommand(command))
+	}
+	return c.AliasCommand(name, ParseCommand(command))
+}
+
+// AliasCommand creates a wrapper named name that runs a structured command
+func (c *Client) AliasCommand(name string, command *Command) (*Result, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errorf(ErrInvalidName, "alias name cannot be empty")
+	}
+
+	normalized, err := prepareCommand(command, c.Options.Literal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := names.Validate(name, runtime.GOOS); err != nil {
+		return nil, err
+	}
+	opts, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := c.checkShadow(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.begin("alias", name)
+	result, err := c.handler.HandleAlias(name, normalized, "install", opts)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
+
+// options returns c.Options with its tags normalized and its variants checked
+func (c *Client) options() (Options, error) {
+	opts := c.Options
+	tags, err := config.NormalizeTags(opts.Tags)
+	if err != nil {
+		return opts, errorf(ErrInvalidTag, "%v", err)
+	}
+	opts.Tags = tags
+	if opts.Variants, err = prepareVariants(opts.Variants, opts.Literal); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// prepareVariants checks per-OS command variants. The variant for this OS is
+// prepared like any alias command; the others are kept as given, since their
+// programs can only be resolved where they run.
+func prepareVariants(variants map[string]*Command, literal bool) (map[string]*Command, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	prepared := make(map[string]*Command, len(variants))
+	for goos, command := range variants {
+		known := false
+		for _, name := range config.VariantOSes {
+			known = known || goos == name
+		}
+		if !known {
+			return nil, errorf(ErrInvalidCommand, "unknown operating system '%s' for a variant (want %s)", goos, strings.Join(config.VariantOSes, ", "))
+		}
+		if command == nil || strings.TrimSpace(command.Program()) == "" {
+			return nil, errorf(ErrInvalidCommand, "invalid command for %s: command cannot be empty", goos)
+		}
+		if goos != runtime.GOOS {
+			prepared[goos] = command
+			continue
+		}
+		normalized, err := prepareCommand(command, literal)
+		if err != nil {
+			return nil, err
+		}
+		prepared[goos] = normalized
+	}
+	return prepared, nil
+}
+
+// prepareCommand resolves the program and explicit ./ arguments of command;
+// everything else is evaluated by the wrapper when it runs. Literal commands
+// are only checked to be non-empty.
+func prepareCommand(command *Command, literal bool) (*Command, error) {
+	if literal {
+		if command == nil || strings.TrimSpace(command.Program()) == "" {
+			return nil, errorf(ErrInvalidCommand, "invalid command: command cannot be empty")
+		}
+		return command, nil
+	}
+	normalized, err := normalizeCommand(command)
+	if err != nil {
+		return nil, errorf(ErrInvalidCommand, "invalid command '%s': %v", command, err)
+	}
+	return normalized, nil
+}
+
+// Remove removes the binary or alias called name. For binaries a path may be
+// given instead of a name, in which case its base name is used.
+func (c *Client) Remove(name string) (*Result, error) {
+	entry, err := c.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.begin("remove", entry.Name)
+	result, err := c.uninstall(entry)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	return result, nil
+}
+
+// uninstall removes an entry's files and config record through the OS handler
+func (c *Client) uninstall(entry *Entry) (*Result, error) {
+	if entry.IsAlias() {
+		return c.handler.HandleAlias(entry.Name, nil, "remove", Options{})
+	}
+	return c.handler.Handle(entry.SourcePath, entry.Name, "remove", Options{})
+}
+
+// Unalias removes the alias called name; unlike Remove it refuses binaries
+func (c *Client) Unalias(name string) (*Result, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errorf(ErrInvalidName, "alias name cannot be empty")
+	}
+	p := c.begin("remove", name)
+	result, err := c.handler.HandleAlias(name, nil, "remove", Options{})
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	return result, nil
+}
+
+// List returns every entry in the lnb config, sorted by name. Use Find to
+// filter or order them differently.
+func (c *Client) List() ([]*Entry, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries := cfg.List()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// lookup finds the entry for a name, falling back to the base name of a path
+// and to the name without its extension (Windows wrappers drop it)
+func (c *Client) lookup(name string) (*Entry, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errorf(ErrInvalidName, "name cannot be empty")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(name)
+	for _, candidate := range []string{name, base, strings.TrimSuffix(base, filepath.Ext(base))} {
+		if entry, exists := cfg.GetEntry(candidate); exists {
+			return entry, nil
+		}
+	}
+
+	return nil, errorf(ErrNotInstalled, "'%s' was not installed by LNB", name)
+}
@@ -0,0 +1,227 @@
+package lnb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"lnb/internal/config"
+)
+
+// newTestClient returns a Client whose config, bin dir and home are temporary
+// directories. PATH holds the bin dir, a directory with a command called
+// "taken" after it, and the system directories.
+func newTestClient(t *testing.T) (*Client, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fixtures are shell scripts")
+	}
+	binDir := t.TempDir()
+	otherDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(otherDir, "taken"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LNB_TEST_CONFIG_DIR", t.TempDir())
+	t.Setenv("LNB_BIN_DIR", binDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", strings.Join([]string{binDir, otherDir, "/usr/bin", "/bin"}, string(os.PathListSeparator)))
+
+	client, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, binDir
+}
+
+func TestClientErrors(t *testing.T) {
+	c, binDir := newTestClient(t)
+	assets := t.TempDir()
+
+	tool := filepath.Join(assets, "lnbtool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\necho tool\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(assets, "plain")
+	if err := os.WriteFile(plain, []byte("data\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "foreign"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".lnb.yaml"), []byte("aliases:\n  build: make\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Install(tool); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	for _, name := range []string{"greet", "moved", "changed"} {
+		if _, err := c.Alias(name, "echo "+name); err != nil {
+			t.Fatalf("Alias(%s): %v", name, err)
+		}
+	}
+	if _, err := c.Update("changed", ParseCommand("echo again")); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	records, err := c.History()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("History() = %v, %v", records, err)
+	}
+	var aliased int // the record of the alias that was changed since
+	for _, record := range records {
+		if record.Op == "alias" && record.Names()[0] == "changed" {
+			aliased = record.ID
+		}
+	}
+
+	tests := []struct {
+		name string
+		want error
+		run  func() error
+	}{
+		{"invalid alias name", ErrInvalidName, func() error { _, err := c.Alias("../evil", "echo x"); return err }},
+		{"empty move target", ErrInvalidName, func() error { _, err := c.Move("greet", " "); return err }},
+		{"empty command", ErrInvalidCommand, func() error { _, err := c.AliasCommand("empty", &Command{}); return err }},
+		{"alias shadowing a command", ErrShadowed, func() error { _, err := c.Alias("taken", "echo x"); return err }},
+		{"missing binary", ErrNotExist, func() error { _, err := c.Install(filepath.Join(assets, "missing")); return err }},
+		{"file that is not executable", ErrNotExecutable, func() error { _, err := c.Install(plain); return err }},
+		{"alias installed twice", ErrAlreadyInstalled, func() error { _, err := c.Alias("greet", "echo again"); return err }},
+		{"binary installed twice", ErrAlreadyInstalled, func() error { _, err := c.Install(tool); return err }},
+		{"move onto an entry", ErrAlreadyInstalled, func() error { _, err := c.Move("greet", "moved"); return err }},
+		{"file lnb did not write", ErrTargetExists, func() error { _, err := c.Alias("foreign", "echo x"); return err }},
+		{"remove an unknown name", ErrNotInstalled, func() error { _, err := c.Remove("nothing"); return err }},
+		{"unalias an unknown name", ErrNotInstalled, func() error { _, err := c.Unalias("nothing"); return err }},
+		{"edit a binary", ErrNotAlias, func() error { _, err := c.Spec("lnbtool"); return err }},
+		{"bad sort order", ErrInvalidQuery, func() error { _, err := c.Find(Query{Sort: "size"}); return err }},
+		{"bad regular expression", ErrInvalidQuery, func() error { _, err := c.Find(Query{Pattern: "("}); return err }},
+		{"bad tag", ErrInvalidTag, func() error { _, err := c.Tag("greet", []string{"two words"}, nil); return err }},
+		{"unknown profile", ErrNoProfile, func() error { _, err := c.UseProfile("nope"); return err }},
+		{"delete the profile in use", ErrProfileInUse, func() error { return c.DeleteProfile(DefaultProfile) }},
+		{"untrusted project file", ErrUntrusted, func() error { _, err := c.Local("build", project); return err }},
+		{"unknown operation", ErrNoRecord, func() error { _, _, err := c.Undo(99); return err }},
+		{"undo before a later change", ErrChanged, func() error { _, _, err := c.Undo(aliased); return err }},
+		{"bundle of another version", ErrInvalidBundle, func() error { _, err := c.Import(&Bundle{Version: "0"}, ConflictSkip); return err }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run()
+			if !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want an error matching %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientTargetMismatch(t *testing.T) {
+	c, binDir := newTestClient(t)
+	if _, err := c.Alias("greet", "echo hi"); err != nil {
+		t.Fatalf("Alias: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := cfg.GetEntry("greet")
+	entry.TargetPath = filepath.Join(binDir, "elsewhere")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Remove("greet"); !errors.Is(err, ErrTargetMismatch) {
+		t.Errorf("Remove of a moved target = %v, want an error matching ErrTargetMismatch", err)
+	}
+}
+
+func TestClientNothingToUndo(t *testing.T) {
+	c, _ := newTestClient(t)
+	if _, _, err := c.Undo(0); !errors.Is(err, ErrNoRecord) {
+		t.Errorf("Undo(0) with no history = %v, want an error matching ErrNoRecord", err)
+	}
+}
+
+func TestMoveKeepsFilesWhenConfigCannotBeSaved(t *testing.T) {
+	c, binDir := newTestClient(t)
+	if _, err := c.Alias("greet", "echo hi"); err != nil {
+		t.Fatalf("Alias: %v", err)
+	}
+
+	// A directory where the config's temporary file goes makes Save fail
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(configPath+".tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Move("greet", "hello"); err == nil {
+		t.Fatal("Move succeeded without saving the config")
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "greet")); err != nil {
+		t.Errorf("the old wrapper was not put back: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(binDir, "hello")); !os.IsNotExist(err) {
+		t.Errorf("the new wrapper was left behind: %v", err)
+	}
+}
+
+func TestRemoveDesktopEntryWithoutFiles(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("menu entries are only made on Linux")
+	}
+	c, binDir := newTestClient(t)
+	tool := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Install(tool); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+
+	// An older or hand-edited config can mark a menu entry without its files
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := cfg.GetEntry("tool")
+	entry.Desktop = true
+	entry.ExtraTargets = nil
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Remove("tool"); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(binDir, "tool")); !os.IsNotExist(err) {
+		t.Errorf("the link was not removed: %v", err)
+	}
+}
+
+func TestLocalAliasLeavesGlobalAliasWithUntrustedProject(t *testing.T) {
+	c, _ := newTestClient(t)
+	if _, err := c.Alias("build", "echo global"); err != nil {
+		t.Fatalf("Alias: %v", err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ProjectFile), []byte("aliases:\n  test: make test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := c.LocalAlias("build", "make", project); !errors.Is(err, ErrUntrusted) {
+		t.Fatalf("LocalAlias with an untrusted project file = %v, want an error matching ErrUntrusted", err)
+	}
+	entries, err := c.Find(Query{Glob: "build"})
+	if err != nil || len(entries) != 1 || entries[0].Kind != config.KindAlias {
+		t.Errorf("the global alias was changed: %+v, %v", entries, err)
+	}
+	if records, _ := c.History(); len(records) != 1 {
+		t.Errorf("the history has %d records, want only the alias", len(records))
+	}
+}
@@ -0,0 +1,264 @@
+package lnb
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/names"
+	"lnb/internal/oshandler"
+	"lnb/internal/project"
+)
+
+// ProjectFile is the name of the file local aliases are defined in
+const ProjectFile = project.FileName
+
+// LocalAlias defines name as the command line command in the project file of
+// the project dir belongs to: the nearest .lnb.yaml in or above dir, else one
+// in the root of the git work tree, else one in dir. It makes sure a wrapper
+// for name runs the nearest definition; an existing plain alias called name
+// becomes the fallback used outside projects that define it. The project file
+// written is returned and trusted.
+func (c *Client) LocalAlias(name, command, dir string) (*Result, string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, "", errorf(ErrInvalidCommand, "invalid command '%s': command cannot be empty", command)
+	}
+	if err := names.Validate(name, runtime.GOOS); err != nil {
+		return nil, "", err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The project file is checked before the wrapper is touched
+	file, err := project.Load(filepath.Join(project.Root(dir), project.FileName))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := c.checkTrusted(file); err != nil {
+		return nil, "", err
+	}
+
+	p := c.begin("alias", name)
+	result, err := c.dispatcher(name)
+	if err != nil {
+		return nil, "", err
+	}
+	p.finish(result)
+
+	file.Set(name, command)
+	if err := file.Save(); err != nil {
+		return nil, "", err
+	}
+	if err := c.trust(file); err != nil {
+		return nil, "", err
+	}
+	result.Command = command
+	return result, file.Path, nil
+}
+
+// RemoveLocalAlias removes the definition of name from the nearest project
+// file in or above dir that has one, and returns that file. The wrapper stays,
+// since other projects may define name too; Unalias removes it.
+func (c *Client) RemoveLocalAlias(name, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	file, err := project.Find(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if file == nil {
+		return "", errorf(ErrNotInstalled, "no %s in %s or above defines '%s'", project.FileName, dir, name)
+	}
+	if err := c.checkTrusted(file); err != nil {
+		return "", err
+	}
+	file.Remove(name)
+	if err := file.Save(); err != nil {
+		return "", err
+	}
+	return file.Path, c.trust(file)
+}
+
+// Trust allows local aliases to run the nearest project file in or above dir
+// as it is now, and returns its path. Project files lnb writes itself are
+// trusted already; one written or changed by anyone else, e.g. checked out
+// with a repository, has to be trusted before its aliases run.
+func (c *Client) Trust(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	path, ok := project.Nearest(dir)
+	if !ok {
+		return "", errorf(ErrNotExist, "there is no %s in %s or above", project.FileName, dir)
+	}
+	file, err := project.Load(path)
+	if err != nil {
+		return "", err
+	}
+	return path, c.trust(file)
+}
+
+// LocalCommand is what a local alias runs in a particular directory
+type LocalCommand struct {
+	Name   string
+	Line   string   // the command line from the project file
+	Dir    string   // the project directory Line runs in
+	Source string   // the project file, empty when Fallback runs instead
+	Global *Command // the fallback alias, when no project defines Name
+}
+
+// Local finds what the local alias called name runs in dir: the nearest
+// project definition, else its fallback
+func (c *Client) Local(name, dir string) (*LocalCommand, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	file, err := project.Find(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		if err := c.checkTrusted(file); err != nil {
+			return nil, err
+		}
+		line, _ := file.Get(name)
+		return &LocalCommand{Name: name, Line: line, Dir: filepath.Dir(file.Path), Source: file.Path}, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if entry, exists := cfg.GetEntry(name); exists && entry.Fallback != nil {
+		return &LocalCommand{Name: name, Global: entry.Fallback}, nil
+	}
+	return nil, errorf(ErrNotInstalled, "'%s' is not defined in a %s here or in any directory above, and has no global alias", name, project.FileName)
+}
+
+// Cmd returns the command to run with args appended. A project definition
+// runs in its project directory through the shell, like an alias wrapper;
+// the fallback runs where it was set up to.
+func (l *LocalCommand) Cmd(args []string) *exec.Cmd {
+	if l.Global == nil {
+		return project.Command(l.Line, l.Dir, args)
+	}
+
+	var cmd *exec.Cmd
+	if l.Global.Shell {
+		cmd = project.Command(l.Global.String(), l.Global.Dir, args)
+	} else {
+		argv := append(append([]string(nil), l.Global.Argv[1:]...), args...)
+		cmd = exec.Command(l.Global.Program(), argv...)
+		cmd.Dir = l.Global.Dir
+	}
+	cmd.Env = os.Environ()
+	for _, key := range l.Global.EnvKeys() {
+		cmd.Env = append(cmd.Env, key+"="+l.Global.Env[key])
+	}
+	return cmd
+}
+
+// dispatcher makes sure there is a wrapper for the local alias called name
+func (c *Client) dispatcher(name string) (*Result, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	runner, err := c.runner()
+	if err != nil {
+		return nil, err
+	}
+	command := config.NewCommand(runner, "run", name)
+
+	entry, exists := cfg.GetEntry(name)
+	switch {
+	case exists && entry.Kind == config.KindLocal:
+		return &Result{Name: name, TargetPath: entry.TargetPath}, nil
+
+	case exists && entry.Kind == config.KindAlias:
+		// The alias becomes what runs outside the projects
+		opts := oshandler.EntryOptions(entry)
+		opts.Local = true
+		opts.Fallback = entry.Command
+		if _, err := c.uninstall(entry); err != nil {
+			return nil, err
+		}
+		result, err := c.handler.HandleAlias(name, command, "install", opts)
+		if err != nil {
+			return nil, c.restore(entry, err)
+		}
+		if err := keepHistory(entry, name, true); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to update config: %v", err))
+		}
+		result.Notes = append(result.Notes, fmt.Sprintf("the global alias '%s' still runs outside projects that define it", name))
+		return result, nil
+
+	case exists:
+		return nil, errorf(ErrAlreadyInstalled, "'%s' is a %s, not an alias. Use 'lnb remove %s' first to replace it", name, entry.Kind, name)
+	}
+
+	opts, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	opts.Local = true
+	warnings, err := c.checkShadow(name)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.handler.HandleAlias(name, command, "install", opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
+
+// runner returns the lnb executable local alias wrappers call
+func (c *Client) runner() (string, error) {
+	if c.Runner != "" {
+		return filepath.Abs(c.Runner)
+	}
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// checkTrusted refuses a project file that exists but was neither written by
+// lnb nor trusted as it is now
+func (c *Client) checkTrusted(file *project.File) error {
+	if file.Content() == "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !cfg.IsTrusted(file.Path, file.Sum()) {
+		return errorf(ErrUntrusted, "%s was changed outside lnb or is new to it; review it and run 'lnb trust' in %s to allow it", file.Path, filepath.Dir(file.Path))
+	}
+	return nil
+}
+
+// trust records the project file as it is now as trusted
+func (c *Client) trust(file *project.File) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.Trust(file.Path, file.Sum())
+	return cfg.Save()
+}
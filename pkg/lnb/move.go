@@ -0,0 +1,70 @@
+package lnb
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"lnb/internal/config"
+	"lnb/internal/names"
+	"lnb/internal/oshandler"
+	"lnb/internal/project"
+)
+
+// Move renames the binary or alias called oldName to newName. The new name
+// gets the same checks as a fresh install, and the entry keeps its command or
+// source, launchers, tags, creation time and bin dir. Its files are renamed in
+// place; if one cannot be, the ones already renamed are put back.
+func (c *Client) Move(oldName, newName string) (*Result, error) {
+	entry, err := c.lookup(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Kind == config.KindLocal {
+		return nil, errorf(ErrNotAlias, "'%s' is a local alias; its name is also used in %s files, so it cannot be renamed", entry.Name, project.FileName)
+	}
+	if strings.TrimSpace(newName) == "" {
+		return nil, errorf(ErrInvalidName, "new name cannot be empty")
+	}
+	if newName == entry.Name {
+		return nil, errorf(ErrInvalidName, "'%s' is already called that", entry.Name)
+	}
+	if err := names.Validate(newName, runtime.GOOS); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := cfg.GetEntry(newName); exists {
+		return nil, errorf(ErrAlreadyInstalled, "'%s' is already installed. Use 'lnb remove %s' first to replace it", newName, newName)
+	}
+	var others []string
+	for name := range cfg.Entries {
+		if name != entry.Name {
+			others = append(others, name)
+		}
+	}
+	if err := names.CheckCollision(newName, others, runtime.GOOS); err != nil {
+		return nil, err
+	}
+	warnings, err := c.checkShadow(newName)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := *entry
+	renamed.Name = newName
+	now := time.Now()
+	renamed.UpdatedAt = &now
+
+	p := c.begin("move", entry.Name, newName)
+	result, err := oshandler.Rewrite(c.handler, entry, &renamed)
+	if err != nil {
+		return nil, err
+	}
+	p.finish(result)
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package lnb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/fsops"
+	"lnb/internal/journal"
+	"lnb/internal/project"
+	"lnb/internal/shellrc"
+	"lnb/internal/textdiff"
+)
+
+// PlanAction is what a planned change does at its path
+type PlanAction = fsops.Action
+
+// The changes a plan can hold
+const (
+	PlanMkdir   = fsops.OpMkdir   // create a directory
+	PlanWrite   = fsops.OpWrite   // write a file with Content
+	PlanAppend  = fsops.OpAppend  // add Content to the end of a file
+	PlanSymlink = fsops.OpSymlink // create a symlink pointing at Target
+	PlanRemove  = fsops.OpRemove  // delete a file
+	PlanRename  = fsops.OpRename  // move a file from Path to Target
+	PlanAddPath = fsops.OpAddPath // add Path to the user's PATH outside any file (Windows)
+)
+
+// PlanRole says what a planned change is for
+type PlanRole string
+
+const (
+	RoleEntry    PlanRole = "entry"    // an entry's link, script, wrapper, menu entry, icon or imported binary
+	RoleConfig   PlanRole = "config"   // the config file
+	RoleJournal  PlanRole = "journal"  // the record 'lnb history' and 'lnb undo' use
+	RoleSnapshot PlanRole = "snapshot" // a saved copy of the config
+	RoleShellRC  PlanRole = "path"     // PATH set up in a shell startup file or the registry
+	RoleProject  PlanRole = "project"  // a project's .lnb.yaml
+)
+
+// Planned is one change a dry run found lnb would make
+type Planned struct {
+	Action  PlanAction
+	Role    PlanRole
+	Path    string
+	Target  string      // what a symlink points at, or where a file is renamed to
+	Content string      // what a file is given or has appended
+	Mode    os.FileMode // permissions of a written file
+	Before  *string     // what a written file held before, nil if it is new
+}
+
+// Diff returns how a write changes a file that exists as a unified diff, or
+// "" if the file is new or unchanged
+func (p *Planned) Diff() string {
+	if p.Action != PlanWrite || p.Before == nil || *p.Before == p.Content {
+		return ""
+	}
+	return textdiff.Unified(p.Path+" (now)", p.Path+" (planned)", *p.Before, p.Content)
+}
+
+// Plan runs fn as a dry run: the file changes the Client makes inside it are
+// recorded instead of made, and returned in order along with fn's error. Code
+// in fn reads the recorded changes back as if they had been made, so a plan
+// takes the same path a real run would. Changes made outside lnb's files, such
+// as by git or by a command an alias runs, are not covered. Plan is not safe
+// to use from more than one goroutine.
+func Plan(fn func() error) ([]*Planned, error) {
+	var roles func(fsops.Op) PlanRole
+	ops, err := fsops.Record(func() error {
+		err := fn()
+		roles = planRoles()
+		return err
+	})
+
+	planned := make([]*Planned, len(ops))
+	for i, op := range ops {
+		planned[i] = &Planned{Action: op.Action, Role: roles(op), Path: op.Path, Target: op.Target, Content: op.Content, Mode: op.Mode, Before: op.Before}
+	}
+	return planned, err
+}
+
+// planRoles returns a function telling what a planned change is for, from
+// where lnb keeps its own files
+func planRoles() func(fsops.Op) PlanRole {
+	configPath, _ := config.GetConfigPath()
+	journalPath, _ := journal.Path()
+	snapshots, _ := config.DataPath("snapshots")
+
+	rcFiles := make(map[string]bool)
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, shell := range shellrc.Detect(home) {
+			rcFiles[filepath.Clean(shell.RCFile)] = true
+		}
+	}
+
+	return func(op fsops.Op) PlanRole {
+		path := filepath.Clean(op.Path)
+		switch {
+		case op.Action == fsops.OpAddPath || rcFiles[path]:
+			return RoleShellRC
+		case path == journalPath:
+			return RoleJournal
+		case snapshots != "" && (path == snapshots || strings.HasPrefix(path, snapshots+string(filepath.Separator))):
+			return RoleSnapshot
+		case configPath != "" && (path == filepath.Dir(configPath) || strings.HasPrefix(path, configPath)):
+			return RoleConfig
+		case filepath.Base(path) == project.FileName:
+			return RoleProject
+		}
+		return RoleEntry
+	}
+}
@@ -0,0 +1,232 @@
+package lnb
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"lnb/internal/config"
+	"lnb/internal/oshandler"
+)
+
+// DefaultProfile is the profile entries belong to until another one is used
+const DefaultProfile = config.DefaultProfile
+
+// Profile summarizes one profile for Profiles
+type Profile struct {
+	Name    string
+	Active  bool
+	BinDir  string // where the profile's entries are created; empty means the usual bin directory
+	Entries int
+}
+
+// Profiles returns every profile, sorted by name
+func (c *Client) Profiles() ([]*Profile, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.GetProfile(name)
+		profiles = append(profiles, &Profile{
+			Name:    name,
+			Active:  name == cfg.ActiveProfile(),
+			BinDir:  profile.BinDir,
+			Entries: len(cfg.ProfileEntries(name)),
+		})
+	}
+	return profiles, nil
+}
+
+// ActiveProfile returns the name of the profile in use
+func (c *Client) ActiveProfile() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ActiveProfile(), nil
+}
+
+// ProfileEntries returns the entries of the profile called name, whether it
+// is in use or not, sorted by name
+func (c *Client) ProfileEntries(name string) ([]*Entry, error) {
+	cfg, err := c.loadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	entries := cfg.ProfileEntries(name)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// CreateProfile adds an empty profile. Its entries are created in binDir
+// while it is in use, or in the usual bin directory when binDir is empty.
+func (c *Client) CreateProfile(name, binDir string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return errorf(ErrInvalidName, "%v", err)
+	}
+	dir, err := absBinDir(binDir)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if _, exists := cfg.GetProfile(name); exists {
+		return errorf(ErrAlreadyInstalled, "profile '%s' already exists", name)
+	}
+	if _, err := cfg.AddProfile(name, dir); err != nil {
+		return err
+	}
+	return cfg.Save()
+}
+
+// SetProfileBinDir changes where a profile's entries are created. Entries that
+// exist already stay where they are until the profile is used again.
+func (c *Client) SetProfileBinDir(name, binDir string) error {
+	dir, err := absBinDir(binDir)
+	if err != nil {
+		return err
+	}
+	cfg, err := c.loadProfile(name)
+	if err != nil {
+		return err
+	}
+	cfg.SetProfileBinDir(name, dir)
+	return cfg.Save()
+}
+
+// DeleteProfile removes a profile that is not in use. Its entries have no
+// files while the profile is not in use, so only their records go.
+func (c *Client) DeleteProfile(name string) error {
+	cfg, err := c.loadProfile(name)
+	if err != nil {
+		return err
+	}
+	if name == cfg.ActiveProfile() {
+		return errorf(ErrProfileInUse, "profile '%s' is in use; switch to another one first", name)
+	}
+	if name == DefaultProfile {
+		return errorf(ErrProfileInUse, "the default profile cannot be deleted")
+	}
+	cfg.RemoveProfile(name)
+	return cfg.Save()
+}
+
+// UseProfile switches to the profile called name: the files of the entries in
+// use are removed and those of the new profile's entries are created in its
+// bin directory. If the entries in use cannot be taken down, the previous
+// profile is put back. An entry of the new profile that cannot be created
+// keeps its record and is reported with a warning. It returns what creating
+// each entry of the new profile reported.
+func (c *Client) UseProfile(name string) ([]*Result, error) {
+	cfg, err := c.loadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	previous := cfg.ActiveProfile()
+	if name == previous {
+		return nil, nil
+	}
+
+	current, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.takeDown(current); err != nil {
+		return nil, err
+	}
+
+	// The records of the entries just taken down go with their profile
+	if cfg, err = config.Load(); err != nil {
+		return nil, c.bringBack(current, err)
+	}
+	for _, entry := range current {
+		cfg.Entries[entry.Name] = entry
+	}
+	next := cfg.SwapProfile(name)
+	if err := cfg.Save(); err != nil {
+		return nil, c.bringBack(current, err)
+	}
+
+	return c.bringUp(next), nil
+}
+
+// loadProfile loads the config and checks that the profile called name exists
+func (c *Client) loadProfile(name string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := cfg.GetProfile(name); !exists {
+		return nil, errorf(ErrNoProfile, "there is no profile called '%s' (create it with 'lnb profile create %s')", name, name)
+	}
+	return cfg, nil
+}
+
+// takeDown removes the files and records of entries. If one cannot be
+// removed, those already removed are created again.
+func (c *Client) takeDown(entries []*Entry) error {
+	for i, entry := range entries {
+		if _, err := c.uninstall(entry); err != nil {
+			return c.bringBack(entries[:i], fmt.Errorf("'%s': %w", entry.Name, err))
+		}
+	}
+	return nil
+}
+
+// bringUp creates entries again from their records, keeping their history.
+// An entry that cannot be created, such as a binary whose source is gone,
+// keeps its record without files and gets a Result whose warning says why, so
+// that it does not hold the others back. Nothing is removed when one fails.
+func (c *Client) bringUp(entries []*Entry) []*Result {
+	var results []*Result
+	for _, entry := range entries {
+		result, err := c.reinstall(entry, entry.Name, entry.Command, oshandler.EntryOptions(entry))
+		if err == nil {
+			err = keepHistory(entry, entry.Name, false)
+		} else {
+			result = &Result{Name: entry.Name, TargetPath: entry.TargetPath}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("'%s' could not be created, only its record is kept: %v", entry.Name, err))
+			err = keepRecord(entry)
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to update config: %v", err))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// keepRecord puts the record of an entry that could not be created back in
+// the config as it was
+func keepRecord(entry *Entry) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.Entries[entry.Name] = entry
+	return cfg.Save()
+}
+
+// bringBack creates entries taken down by takeDown again after err and
+// returns err; entries that cannot be created keep their records
+func (c *Client) bringBack(entries []*Entry, err error) error {
+	c.bringUp(entries)
+	return err
+}
+
+// absBinDir expands ~ in a bin directory and makes it absolute; empty stays empty
+func absBinDir(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	expanded, err := expandHome(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(expanded)
+}
@@ -0,0 +1,143 @@
+package lnb
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"lnb/internal/atime"
+	"lnb/internal/config"
+)
+
+// Orders for Query.Sort
+const (
+	SortName      = "name"      // alphabetical, the default
+	SortInstalled = "installed" // most recently installed first
+	SortUsed      = "used"      // most recently run first; see LastUsed
+)
+
+// Query selects and orders entries for Find. Empty fields match everything.
+type Query struct {
+	Type    string // entry kind: binary, alias, script, shim, container or local
+	Tag     string // a tag the entry must have
+	Glob    string // shell pattern the name must match, e.g. "git-*"
+	Pattern string // regular expression the name must match
+	Text    string // text the name, description, command or source must contain, ignoring case
+	Sort    string // SortName, SortInstalled or SortUsed
+	Profile string // the profile to look in; empty means the one in use
+}
+
+// Find returns the entries matching q in the order it asks for
+func (c *Client) Find(q Query) ([]*Entry, error) {
+	match, err := q.matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.List()
+	if q.Profile != "" {
+		entries, err = c.ProfileEntries(q.Profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Entry
+	for _, entry := range entries {
+		if match(entry) {
+			found = append(found, entry)
+		}
+	}
+
+	switch q.Sort {
+	case SortInstalled:
+		sort.SliceStable(found, func(i, j int) bool { return found[i].InstalledAt.After(found[j].InstalledAt) })
+	case SortUsed:
+		used := make(map[*Entry]time.Time, len(found))
+		for _, entry := range found {
+			used[entry], _ = LastUsed(entry)
+		}
+		sort.SliceStable(found, func(i, j int) bool { return used[found[i]].After(used[found[j]]) })
+	}
+	return found, nil
+}
+
+// matcher checks the query and returns a function reporting whether an entry matches it
+func (q Query) matcher() (func(*Entry) bool, error) {
+	switch q.Sort {
+	case "", SortName, SortInstalled, SortUsed:
+	default:
+		return nil, errorf(ErrInvalidQuery, "unknown sort order '%s' (want name, installed or used)", q.Sort)
+	}
+
+	switch config.Kind(q.Type) {
+	case "", config.KindBinary, config.KindAlias, config.KindScript, config.KindShim, config.KindContainer, config.KindLocal:
+	default:
+		return nil, errorf(ErrInvalidQuery, "unknown type '%s' (want binary, alias, script, shim, container or local)", q.Type)
+	}
+
+	if _, err := path.Match(q.Glob, ""); err != nil {
+		return nil, errorf(ErrInvalidQuery, "invalid pattern '%s': %v", q.Glob, err)
+	}
+
+	var pattern *regexp.Regexp
+	if q.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(q.Pattern); err != nil {
+			return nil, errorf(ErrInvalidQuery, "invalid regular expression '%s': %v", q.Pattern, err)
+		}
+	}
+
+	text := strings.ToLower(q.Text)
+	return func(entry *Entry) bool {
+		if q.Type != "" && string(entry.Kind) != q.Type {
+			return false
+		}
+		if q.Tag != "" && !entry.HasTag(q.Tag) {
+			return false
+		}
+		if q.Glob != "" {
+			if ok, _ := path.Match(q.Glob, entry.Name); !ok {
+				return false
+			}
+		}
+		if pattern != nil && !pattern.MatchString(entry.Name) {
+			return false
+		}
+		return text == "" || strings.Contains(strings.ToLower(searchText(entry)), text)
+	}, nil
+}
+
+// searchText is what Query.Text is matched against: the name, description
+// and tags, the command with its environment and directory, the source and
+// the container image
+func searchText(entry *Entry) string {
+	parts := append([]string{entry.Name, entry.Description, entry.SourcePath}, entry.Tags...)
+	if entry.Command != nil {
+		parts = append(parts, entry.Command.String(), entry.Command.Dir)
+		for _, key := range entry.Command.EnvKeys() {
+			parts = append(parts, key+"="+entry.Command.Env[key])
+		}
+	}
+	if entry.Container != nil {
+		parts = append(parts, entry.Container.Image)
+	}
+	for _, goos := range config.VariantOSes {
+		if variant, ok := entry.Variants[goos]; ok {
+			parts = append(parts, variant.String())
+		}
+	}
+	if entry.Fallback != nil {
+		parts = append(parts, entry.Fallback.String())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// LastUsed returns when an entry was last run, as far as the file system
+// records it: the access time of the binary a symlink points at, or of the
+// wrapper. It is coarse (often a day) and may be missing altogether.
+func LastUsed(entry *Entry) (time.Time, bool) {
+	return atime.Of(entry.TargetPath)
+}
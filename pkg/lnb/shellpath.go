@@ -0,0 +1,108 @@
+package lnb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"lnb/internal/config"
+	"lnb/internal/shellrc"
+)
+
+// ShellStatus says whether one shell's startup file puts the bin directory on PATH
+type ShellStatus = shellrc.Status
+
+// SetBinDir makes dir the bin directory for new entries. Existing entries stay
+// where they are. An empty dir restores the OS default. A profile with a bin
+// directory of its own keeps using that one; see SetProfileBinDir.
+func (c *Client) SetBinDir(dir string) error {
+	dir, err := absBinDir(dir)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.BinDir = dir
+	return cfg.Save()
+}
+
+// InPath reports whether the bin directory is on the current PATH
+func (c *Client) InPath() bool {
+	return inPath(c.handler.BinDir())
+}
+
+// ShellPath reports, for each detected shell, whether its startup file puts
+// the bin directory on PATH. Windows keeps PATH in the registry instead.
+func (c *Client) ShellPath() ([]ShellStatus, error) {
+	if runtime.GOOS == "windows" {
+		return nil, ErrUnsupportedOS
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return shellrc.Check(shellrc.Detect(home), c.handler.BinDir(), home)
+}
+
+// AddShellPath adds the marked PATH block to the startup file of every
+// detected shell that does not already put the bin directory on PATH. It
+// returns the files it changed.
+func (c *Client) AddShellPath() ([]string, error) {
+	statuses, err := c.ShellPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, status := range statuses {
+		if status.OnPath() {
+			continue
+		}
+		if err := shellrc.Add(status.Shell, c.handler.BinDir()); err != nil {
+			return changed, err
+		}
+		changed = append(changed, status.RCFile)
+	}
+	return changed, nil
+}
+
+// RemoveShellPath removes the marked PATH block from every detected shell's
+// startup file and returns the files it changed
+func (c *Client) RemoveShellPath() ([]string, error) {
+	if runtime.GOOS == "windows" {
+		return nil, ErrUnsupportedOS
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, shell := range shellrc.Detect(home) {
+		removed, err := shellrc.Remove(shell)
+		if err != nil {
+			return changed, err
+		}
+		if removed {
+			changed = append(changed, shell.RCFile)
+		}
+	}
+	return changed, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get home directory: %v", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
@@ -0,0 +1,73 @@
+package lnb
+
+import (
+	"fmt"
+	"sort"
+
+	"lnb/internal/config"
+)
+
+// Snapshot is a saved copy of the config. One is kept automatically at most
+// once a day when the config changes, and the newest config.KeepSnapshots are
+// kept.
+type Snapshot = config.Snapshot
+
+// Snapshots returns the saved copies of the config, newest first
+func (c *Client) Snapshots() ([]*Snapshot, error) {
+	return config.Snapshots()
+}
+
+// TakeSnapshot saves a copy of the config as it is now
+func (c *Client) TakeSnapshot() (*Snapshot, error) {
+	return config.TakeSnapshot()
+}
+
+// RestoreSnapshot puts the config back as it was in the snapshot called
+// name: the files of the entries in use are removed and those of the
+// snapshot's entries are created. The config being replaced is saved as a
+// snapshot first, which is returned; if anything fails it is put back.
+func (c *Client) RestoreSnapshot(name string) (*Snapshot, []*Result, error) {
+	snapshot, err := config.LoadSnapshot(name)
+	if err != nil {
+		return nil, nil, errorf(ErrNotExist, "%v", err)
+	}
+	backup, err := config.TakeSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := c.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.takeDown(current); err != nil {
+		return nil, nil, err
+	}
+
+	results, err := c.replaceConfig(snapshot)
+	if err == nil {
+		return backup, results, nil
+	}
+
+	// Put the previous config back the same way
+	err = fmt.Errorf("cannot restore snapshot '%s': %w", name, err)
+	previous, loadErr := config.LoadSnapshot(backup.Name)
+	if loadErr != nil {
+		return nil, nil, fmt.Errorf("%w (restoring the previous config also failed: %v)", err, loadErr)
+	}
+	if _, restoreErr := c.replaceConfig(previous); restoreErr != nil {
+		return nil, nil, fmt.Errorf("%w (restoring the previous config also failed: %v)", err, restoreErr)
+	}
+	return nil, nil, err
+}
+
+// replaceConfig saves cfg as the config and creates the files of its entries
+func (c *Client) replaceConfig(cfg *config.Config) ([]*Result, error) {
+	entries := cfg.List()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	cfg.Entries = make(map[string]*Entry)
+	if err := cfg.Save(); err != nil {
+		return nil, err
+	}
+	return c.bringUp(entries), nil
+}
@@ -0,0 +1,430 @@
+package lnb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"lnb/internal/config"
+	"lnb/internal/gitrepo"
+	"lnb/internal/names"
+)
+
+// syncEntries is the directory of the sync repository that holds one JSON
+// file per entry, so that git merges and reports changes entry by entry
+const syncEntries = "entries"
+
+// Prefer says which side wins when an entry was changed both here and in the
+// sync repository since the last sync
+type Prefer string
+
+const (
+	PreferNewer  Prefer = "newer"  // the one changed or installed last; the default
+	PreferLocal  Prefer = "local"  // this machine's
+	PreferRemote Prefer = "remote" // the sync repository's
+)
+
+// ParsePrefer checks a conflict preference; empty means PreferNewer
+func ParsePrefer(s string) (Prefer, error) {
+	switch prefer := Prefer(strings.ToLower(s)); prefer {
+	case "":
+		return PreferNewer, nil
+	case PreferNewer, PreferLocal, PreferRemote:
+		return prefer, nil
+	}
+	return "", fmt.Errorf("unknown preference '%s' (want newer, local or remote)", s)
+}
+
+// What a sync did with an entry, for Synced.Action
+const (
+	SyncAdded   = "added"   // created here from the sync repository
+	SyncUpdated = "updated" // replaced here by the sync repository's version
+	SyncRemoved = "removed" // removed here because it was removed there
+	SyncLocal   = "local"   // changed here; SyncPush sends it
+	SyncSkipped = "skipped" // left alone; Note says why
+)
+
+// Synced says what a sync did with one entry
+type Synced struct {
+	Name     string
+	Action   string  // one of the Sync* values
+	Conflict bool    // it was changed on both sides; Action says which side won
+	Note     string  // why it was skipped
+	Result   *Result // what creating or removing it here reported
+	Err      error   // why the sync repository's version could not be applied
+}
+
+// SyncDir returns the clone of the sync repository lnb keeps
+func SyncDir() (string, error) {
+	return config.DataPath("sync")
+}
+
+// SyncInit sets up syncing with the git repository at remote by cloning it
+// next to the config. It returns where the clone is. Nothing is changed
+// until SyncPull or SyncPush is called.
+func (c *Client) SyncInit(remote string) (string, error) {
+	dir, err := SyncDir()
+	if err != nil {
+		return "", err
+	}
+	if repo, err := gitrepo.Open(dir); err == nil {
+		current, _ := repo.Remote()
+		return "", errorf(ErrAlreadyInstalled, "sync is already set up with %s; delete %s to start over", current, dir)
+	}
+	if _, err := gitrepo.Clone(remote, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// SyncRemote returns the git repository lnb syncs with
+func (c *Client) SyncRemote() (string, error) {
+	repo, err := c.syncRepo()
+	if err != nil {
+		return "", err
+	}
+	return repo.Remote()
+}
+
+// SyncPull brings changes from the sync repository into the profile in use
+// through the OS handler. Each entry is reconciled on its own against the
+// last sync: one changed on one side only takes that side's version, and one
+// changed on both sides is settled by prefer. Local changes stay here until
+// SyncPush. The changes are journaled as one operation.
+func (c *Client) SyncPull(prefer Prefer) ([]*Synced, error) {
+	repo, err := c.syncRepo()
+	if err != nil {
+		return nil, err
+	}
+	synced, _, err := c.syncPull(repo, prefer)
+	return synced, err
+}
+
+// SyncPush does what SyncPull does, then writes the entries of the profile in
+// use to the sync repository and pushes them. Binaries outside the home
+// directory are machine-specific and left out; paths under it are written as
+// ~/... . It reports whether anything was pushed. What the pull did is
+// returned even when writing or pushing the entries fails.
+func (c *Client) SyncPush(prefer Prefer) ([]*Synced, bool, error) {
+	repo, err := c.syncRepo()
+	if err != nil {
+		return nil, false, err
+	}
+	synced, remote, err := c.syncPull(repo, prefer)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return synced, false, err
+	}
+	home, _ := os.UserHomeDir()
+	local, excluded, err := syncForms(cfg.List(), home)
+	if err != nil {
+		return synced, false, err
+	}
+	failed := make(map[string]bool)
+	for _, item := range synced {
+		failed[item.Name] = item.Err != nil
+	}
+
+	dir := filepath.Join(repo.Dir, syncEntries)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return synced, false, err
+	}
+	for _, name := range unionNames(local, remote) {
+		path := filepath.Join(dir, name+".json")
+		entry, here := local[name]
+		switch {
+		case excluded[name] || failed[name] || sameEntry(entry, remote[name]):
+			// Leave the repository's version alone
+		case here:
+			data, err := json.MarshalIndent(entry, "", "  ")
+			if err != nil {
+				return synced, false, err
+			}
+			if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+				return synced, false, err
+			}
+		case !c.foreign(remote[name], home):
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return synced, false, err
+			}
+		}
+	}
+
+	host, _ := os.Hostname()
+	committed, err := repo.Commit("lnb sync from " + host)
+	if err != nil || !committed {
+		return synced, false, err
+	}
+	if err := repo.Push(); err != nil {
+		return synced, false, fmt.Errorf("could not push to the sync repository; if it changed meanwhile, run 'lnb sync push' again: %w", err)
+	}
+	if failedPull(synced) {
+		return synced, true, nil
+	}
+	return synced, true, repo.MarkSynced("HEAD")
+}
+
+// syncPull reconciles the profile in use with the sync repository and
+// returns what it did and the repository's entries
+func (c *Client) syncPull(repo *gitrepo.Repo, prefer Prefer) ([]*Synced, map[string]*Entry, error) {
+	// Entries are compared with the last sync; before the first one there is nothing
+	base := make(map[string]*Entry)
+	if commit, synced := repo.Synced(); synced {
+		var err error
+		if base, err = readSyncEntries(repo, commit); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := repo.Fetch(); err != nil {
+		return nil, nil, err
+	}
+	remote := make(map[string]*Entry)
+	ref, exists, err := repo.Upstream()
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		if remote, err = readSyncEntries(repo, ref); err != nil {
+			return nil, nil, err
+		}
+		if err := repo.Reset(ref); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	local, excluded, err := syncForms(cfg.List(), home)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var synced, pulls []*Synced
+	for _, name := range unionNames(base, remote, local) {
+		b, r, l := base[name], remote[name], local[name]
+		if sameEntry(l, r) {
+			continue
+		}
+		item := &Synced{Name: name}
+		switch {
+		case excluded[name]:
+			if r == nil {
+				continue
+			}
+			item.Action, item.Note = SyncSkipped, "here it is a binary outside your home directory, which is not synced"
+		case l == nil && c.foreign(r, home):
+			if sameEntry(r, b) {
+				continue
+			}
+			item.Action, item.Note = SyncSkipped, fmt.Sprintf("%s does not exist on this machine", fromHome(r.SourcePath, home))
+		case sameEntry(l, b):
+			item.Action = remoteAction(l, r)
+		case sameEntry(r, b):
+			item.Action = SyncLocal
+		default:
+			item.Conflict = true
+			item.Action = SyncLocal
+			if prefersRemote(prefer, l, r) {
+				item.Action = remoteAction(l, r)
+			}
+		}
+		synced = append(synced, item)
+		if item.Action != SyncLocal && item.Action != SyncSkipped {
+			pulls = append(pulls, item)
+		}
+	}
+	if len(pulls) > 0 {
+		c.applyPulls(cfg, pulls, remote, home)
+	}
+
+	// The last sync only moves on once every change from the repository is
+	// in, so that one which failed is tried again instead of being taken for
+	// a local removal or change
+	if exists && !failedPull(synced) {
+		if err := repo.MarkSynced(ref); err != nil {
+			return nil, nil, err
+		}
+	}
+	return synced, remote, nil
+}
+
+// applyPulls creates, replaces or removes entries here to match the sync
+// repository, journaled as one operation. Each pull's Result or Err says how
+// it went.
+func (c *Client) applyPulls(cfg *config.Config, pulls []*Synced, remote map[string]*Entry, home string) {
+	pulled := make([]string, len(pulls))
+	for i, item := range pulls {
+		pulled[i] = item.Name
+	}
+	p := c.begin("sync", pulled...)
+	var last *Result
+	for _, item := range pulls {
+		if item.Action == SyncRemoved {
+			if entry, exists := cfg.GetEntry(item.Name); exists {
+				item.Result, item.Err = c.uninstall(entry)
+			}
+		} else {
+			replace := ""
+			if _, exists := cfg.GetEntry(item.Name); exists {
+				replace = item.Name
+			}
+			item.Result, item.Err = c.importEntry(remote[item.Name], item.Name, replace, "", home)
+		}
+		if item.Result != nil {
+			last = item.Result
+		}
+	}
+	if last != nil {
+		p.finish(last)
+	}
+}
+
+// failedPull reports whether a change from the sync repository could not be
+// applied
+func failedPull(synced []*Synced) bool {
+	for _, item := range synced {
+		if item.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// syncRepo opens the clone of the sync repository
+func (c *Client) syncRepo() (*gitrepo.Repo, error) {
+	dir, err := SyncDir()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := gitrepo.Open(dir)
+	if errors.Is(err, gitrepo.ErrNotCloned) {
+		return nil, errorf(ErrNotExist, "sync is not set up; run 'lnb sync init <git-remote>' first")
+	}
+	return repo, err
+}
+
+// foreign reports whether a synced entry is a binary whose source does not
+// exist on this machine, so it can be neither created nor removed here
+func (c *Client) foreign(entry *Entry, home string) bool {
+	if entry == nil || entry.IsAlias() {
+		return false
+	}
+	_, err := os.Stat(fromHome(entry.SourcePath, home))
+	return err != nil
+}
+
+// readSyncEntries reads the entries in the sync repository at ref. Each one
+// must be in a file named after it, and its name must be valid here, since
+// push writes and deletes files by name.
+func readSyncEntries(repo *gitrepo.Repo, ref string) (map[string]*Entry, error) {
+	entries := make(map[string]*Entry)
+	files, err := repo.ReadTree(ref, syncEntries)
+	if err != nil {
+		return nil, err
+	}
+	for path, data := range files {
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Name == "" {
+			return nil, errorf(ErrInvalidBundle, "%s/%s in the sync repository is not an lnb entry", syncEntries, path)
+		}
+		if err := names.Validate(entry.Name, runtime.GOOS); err != nil {
+			return nil, errorf(ErrInvalidBundle, "%s/%s in the sync repository: %v", syncEntries, path, err)
+		}
+		if path != entry.Name+".json" {
+			return nil, errorf(ErrInvalidBundle, "%s/%s in the sync repository holds '%s'; it must be in %s.json", syncEntries, path, entry.Name, entry.Name)
+		}
+		entries[entry.Name] = &entry
+	}
+	return entries, nil
+}
+
+// syncForms returns entries as they are written to the sync repository, and
+// the names of the binaries that are not synced because their source is
+// outside home
+func syncForms(entries []*Entry, home string) (map[string]*Entry, map[string]bool, error) {
+	forms := make(map[string]*Entry)
+	excluded := make(map[string]bool)
+	for _, found := range entries {
+		entry, err := portable(found, home)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !entry.IsAlias() && !strings.HasPrefix(entry.SourcePath, "~") {
+			excluded[entry.Name] = true
+			continue
+		}
+		// How an entry is made and where it came from differ between machines
+		entry.Mode = ""
+		entry.Origin = ""
+		forms[entry.Name] = entry
+	}
+	return forms, excluded, nil
+}
+
+// remoteAction says what taking the sync repository's version r does to the
+// local version l
+func remoteAction(l, r *Entry) string {
+	switch {
+	case r == nil:
+		return SyncRemoved
+	case l == nil:
+		return SyncAdded
+	}
+	return SyncUpdated
+}
+
+// prefersRemote settles an entry changed on both sides. With PreferNewer the
+// version changed last wins; a removal loses to any change.
+func prefersRemote(prefer Prefer, l, r *Entry) bool {
+	switch prefer {
+	case PreferLocal:
+		return false
+	case PreferRemote:
+		return true
+	}
+	return changedAt(r).After(changedAt(l))
+}
+
+// changedAt returns when an entry was last changed; zero if it does not exist
+func changedAt(entry *Entry) time.Time {
+	switch {
+	case entry == nil:
+		return time.Time{}
+	case entry.UpdatedAt != nil:
+		return *entry.UpdatedAt
+	}
+	return entry.InstalledAt
+}
+
+// unionNames returns every name in the maps, sorted
+func unionNames(maps ...map[string]*Entry) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range maps {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,111 @@
+package lnb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"lnb/internal/config"
+)
+
+// Tag adds and removes tags on the entry called name and returns the updated
+// entry. Tags are lowercased; several may be given separated by commas.
+func (c *Client) Tag(name string, add, remove []string) (*Entry, error) {
+	add, err := config.NormalizeTags(add)
+	if err != nil {
+		return nil, errorf(ErrInvalidTag, "%v", err)
+	}
+	remove, err = config.NormalizeTags(remove)
+	if err != nil {
+		return nil, errorf(ErrInvalidTag, "%v", err)
+	}
+
+	return c.annotate(name, func(entry *Entry) error {
+		var kept []string
+		for _, tag := range entry.Tags {
+			if !contains(remove, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		tags, err := config.NormalizeTags(append(kept, add...))
+		entry.Tags = tags
+		return err
+	})
+}
+
+// Describe sets the description of the entry called name; an empty
+// description removes it
+func (c *Client) Describe(name, description string) (*Entry, error) {
+	return c.annotate(name, func(entry *Entry) error {
+		entry.Description = strings.TrimSpace(description)
+		return nil
+	})
+}
+
+// RemoveTagged removes every entry tagged tag. It carries on past entries
+// that cannot be removed and returns their errors joined together.
+func (c *Client) RemoveTagged(tag string) ([]*Result, error) {
+	entries, err := c.Find(Query{Tag: tag})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errorf(ErrNotInstalled, "no entries are tagged '%s'", tag)
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	p := c.begin("remove", names...)
+
+	var results []*Result
+	var errs []error
+	for _, entry := range entries {
+		result, err := c.uninstall(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name, err))
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) > 0 {
+		p.finish(results[0])
+	}
+	return results, errors.Join(errs...)
+}
+
+// annotate changes the metadata of the entry called name and saves it
+func (c *Client) annotate(name string, change func(*Entry) error) (*Entry, error) {
+	found, err := c.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.begin("tag", found.Name)
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entry, exists := cfg.GetEntry(found.Name)
+	if !exists {
+		return nil, errorf(ErrNotInstalled, "'%s' was not installed by LNB", name)
+	}
+	if err := change(entry); err != nil {
+		return nil, err
+	}
+	if err := cfg.Save(); err != nil {
+		return nil, err
+	}
+	return entry, p.done()
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
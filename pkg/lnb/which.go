@@ -0,0 +1,54 @@
+package lnb
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"lnb/internal/resolve"
+)
+
+// Resolution is the PATH lookup chain for a name, in the order a shell tries it
+type Resolution = resolve.Resolution
+
+// ShadowError is returned when a new entry would shadow, or be shadowed by,
+// a command already on PATH and AllowShadow is not set
+type ShadowError struct {
+	Name       string
+	Shadows    []string // commands the new entry would take precedence over
+	ShadowedBy []string // commands that would take precedence over the new entry
+}
+
+func (e *ShadowError) Error() string {
+	var parts []string
+	if len(e.Shadows) > 0 {
+		parts = append(parts, fmt.Sprintf("would shadow %s", strings.Join(e.Shadows, ", ")))
+	}
+	if len(e.ShadowedBy) > 0 {
+		parts = append(parts, fmt.Sprintf("would be shadowed by %s", strings.Join(e.ShadowedBy, ", ")))
+	}
+	return fmt.Sprintf("'%s' %s", e.Name, strings.Join(parts, " and "))
+}
+
+func (e *ShadowError) Unwrap() error { return ErrShadowed }
+
+// Which resolves name against the current PATH
+func (c *Client) Which(name string) *Resolution {
+	return resolve.Lookup(name, c.handler.BinDir(), os.Getenv("PATH"), os.Getenv("PATHEXT"), runtime.GOOS)
+}
+
+// checkShadow returns a ShadowError if a new entry called name would collide
+// with another command on PATH. With AllowShadow set the collision is
+// returned as warnings instead.
+func (c *Client) checkShadow(name string) ([]string, error) {
+	resolution := c.Which(name)
+	shadowErr := &ShadowError{Name: name, Shadows: resolution.Shadows(), ShadowedBy: resolution.ShadowedBy()}
+	if len(shadowErr.Shadows) == 0 && len(shadowErr.ShadowedBy) == 0 {
+		return nil, nil
+	}
+	if !c.AllowShadow {
+		return nil, shadowErr
+	}
+	return []string{shadowErr.Error()}, nil
+}